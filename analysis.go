@@ -203,18 +203,6 @@ func (r Range) Delta() float64 {
 	return delta
 }
 
-type Structure struct {
-	RotorSpeedRange Range       `json:"RotorSpeedRange"`
-	Curve           []Condition `json:"Curve"`
-	OperatingPoints []Condition `json:"OperatingPoints"`
-}
-
-type AeroStructure struct {
-	WindSpeedRange  Range       `json:"WindSpeedRange"`
-	Curve           []Condition `json:"Curve"`
-	OperatingPoints []Condition `json:"OperatingPoints"`
-}
-
 func (an *Analysis) CalculateCases() error {
 
 	for i := range an.Cases {
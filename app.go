@@ -2,6 +2,7 @@ package main
 
 import (
 	"acdc/diagram"
+	"acdc/lin"
 	"acdc/viz"
 	"bytes"
 	"context"
@@ -125,6 +126,105 @@ func (a *App) OpenProject(path string) (*Info, error) {
 	return &a.Project.Info, nil
 }
 
+// ExportBundleDialog opens a dialog to select where to save the project
+// bundle and writes the project, model, and selected case results to it.
+func (a *App) ExportBundleDialog(opts BundleOptions) error {
+
+	// Open dialog so user can select the file
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Project Bundle",
+		DefaultFilename: filepath.Base(a.Project.RootPath()) + ".zip",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Project Bundles (*.zip)", Pattern: "*.zip"},
+		},
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return fmt.Errorf("error selecting bundle file: %w", err)
+	}
+
+	// If path not selected, return
+	if path == "" {
+		return nil
+	}
+
+	// Export project bundle
+	return a.Project.ExportBundle(path, opts)
+}
+
+// ImportBundleDialog opens dialogs to select a project bundle and the
+// directory to extract it into, then opens the imported project.
+func (a *App) ImportBundleDialog() (*Info, error) {
+
+	// Open dialog so user can select the bundle
+	bundlePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import Project Bundle",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Project Bundles (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error selecting bundle file: %w", err)
+	}
+	if bundlePath == "" {
+		return nil, fmt.Errorf("no file selected")
+	}
+
+	// Open dialog so user can select the destination directory
+	dstDir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Select Project Directory",
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error selecting project directory: %w", err)
+	}
+	if dstDir == "" {
+		return nil, fmt.Errorf("no directory selected")
+	}
+
+	// Import bundle
+	a.Project, err = ImportBundle(bundlePath, dstDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// set window title
+	runtime.WindowSetTitle(a.ctx, "ACDC - "+a.Project.Info.Path)
+
+	return &a.Project.Info, nil
+}
+
+// FetchUndoState returns the current project state and whether undo and redo
+// are available.
+func (a *App) FetchUndoState() (*UndoState, error) {
+
+	if a.Project == nil {
+		return nil, fmt.Errorf("project not loaded")
+	}
+
+	return a.Project.UndoState(), nil
+}
+
+// Undo restores the previous analysis, evaluate, and diagram state
+func (a *App) Undo() (*UndoState, error) {
+
+	if a.Project == nil {
+		return nil, fmt.Errorf("project not loaded")
+	}
+
+	return a.Project.Undo()
+}
+
+// Redo restores the analysis, evaluate, and diagram state that was undone
+func (a *App) Redo() (*UndoState, error) {
+
+	if a.Project == nil {
+		return nil, fmt.Errorf("project not loaded")
+	}
+
+	return a.Project.Redo()
+}
+
 //------------------------------------------------------------------------------
 // Model
 //------------------------------------------------------------------------------
@@ -502,8 +602,59 @@ func (a *App) EvaluateCase(caseID int) ([]EvalStatus, error) {
 		return []EvalStatus{}, err
 	}
 
-	// Evaluate case
-	return a.Project.Evaluate.Case(a.ctx, a.Project.Model, c, a.Project.RootPath())
+	// Create new run for this evaluation
+	run, err := a.Project.AddRun(c)
+	if err != nil {
+		return []EvalStatus{}, err
+	}
+
+	// Evaluate case in run directory
+	return a.Project.Evaluate.Case(a.ctx, a.Project.Model, c, a.Project.RunDir(run))
+}
+
+// FetchRuns returns the evaluation runs of the case
+func (a *App) FetchRuns(caseID int) ([]Run, error) {
+
+	if a.Project == nil {
+		return nil, fmt.Errorf("project not loaded")
+	}
+
+	return a.Project.CaseRuns(caseID), nil
+}
+
+// UpdateRun updates the label of the run and saves the project
+func (a *App) UpdateRun(run Run) ([]Run, error) {
+
+	if a.Project == nil {
+		return nil, fmt.Errorf("project not loaded")
+	}
+
+	// Find run
+	r, err := a.Project.Run(run.CaseID, run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update label
+	r.Label = run.Label
+
+	// Save project
+	if _, err := a.Project.Save(); err != nil {
+		return nil, err
+	}
+
+	return a.Project.CaseRuns(run.CaseID), nil
+}
+
+// RemoveRun deletes the run and its results
+func (a *App) RemoveRun(caseID, runID int) ([]Run, error) {
+	if a.Project == nil {
+		return nil, fmt.Errorf("project not loaded")
+	}
+	if err := a.Project.RemoveRun(caseID, runID); err != nil {
+		return nil, err
+	}
+	return a.Project.CaseRuns(caseID), nil
 }
 
 func (a *App) CancelEvaluate() {
@@ -524,20 +675,14 @@ func (a *App) GetEvaluateLog(path string) (string, error) {
 
 type LinDirData struct {
 	Dir     string           `json:"Dir"`
+	Run     *Run             `json:"Run"`
 	Results *Results         `json:"Results"`
 	Diagram *diagram.Diagram `json:"Diagram"`
 }
 
-func (a *App) SelectCaseLinDir(caseID int) (LinDirData, error) {
-
-	// Find case
-	c, err := a.Project.Case(caseID)
-	if err != nil {
-		return LinDirData{}, err
-	}
-
-	// Build case directory
-	linDir := filepath.Join(a.Project.RootPath(), fmt.Sprintf("Case%02d", c.ID))
+// loadLinDir loads the results and diagram from the linearization directory
+// into the project and records the loaded state in the undo history.
+func (a *App) loadLinDir(linDir string) (LinDirData, error) {
 
 	// Create linearization directory data structure
 	ld := LinDirData{Dir: linDir}
@@ -549,13 +694,81 @@ func (a *App) SelectCaseLinDir(caseID int) (LinDirData, error) {
 	} else {
 		fmt.Println(err)
 	}
-	if diag, err := diagram.Load(filepath.Join(linDir, "diagram.json")); err == nil {
+	if diag, err := LoadDiagram(filepath.Join(linDir, "diagram.json")); err == nil {
 		a.Project.Diagram = diag
 		ld.Diagram = diag
 	} else {
 		fmt.Println(err)
 	}
 
+	// Record loaded results in undo history
+	if err := a.Project.recordHistory(); err != nil {
+		return LinDirData{}, err
+	}
+
+	return ld, nil
+}
+
+func (a *App) SelectCaseLinDir(caseID int) (LinDirData, error) {
+
+	// Find case
+	c, err := a.Project.Case(caseID)
+	if err != nil {
+		return LinDirData{}, err
+	}
+
+	// Load results from most recent run of case
+	ld, err := a.loadLinDir(a.Project.LatestResultsDir(c.ID))
+	if err != nil {
+		return LinDirData{}, err
+	}
+	if runs := a.Project.CaseRuns(c.ID); len(runs) > 0 {
+		ld.Run = &runs[len(runs)-1]
+	}
+
+	return ld, nil
+}
+
+// SelectRunLinDir loads the results and diagram of the given run
+func (a *App) SelectRunLinDir(caseID, runID int) (LinDirData, error) {
+
+	// Find run
+	run, err := a.Project.Run(caseID, runID)
+	if err != nil {
+		return LinDirData{}, err
+	}
+
+	// Load run results
+	ld, err := a.loadLinDir(a.Project.RunDir(run))
+	if err != nil {
+		return LinDirData{}, err
+	}
+	ld.Run = run
+
+	return ld, nil
+}
+
+// FetchRunData returns the results and diagram of the given run without
+// changing the results loaded in the project, for comparing runs.
+func (a *App) FetchRunData(caseID, runID int) (LinDirData, error) {
+
+	// Find run
+	run, err := a.Project.Run(caseID, runID)
+	if err != nil {
+		return LinDirData{}, err
+	}
+
+	// Load results and diagram
+	ld := LinDirData{Dir: a.Project.RunDir(run), Run: run}
+	results, err := LoadResults(ld.Dir)
+	if err != nil {
+		return LinDirData{}, fmt.Errorf("error loading results for %s: %w", run.Label, err)
+	}
+	ld.Results = results.ForApp()
+	if diag, err := LoadDiagram(filepath.Join(ld.Dir, "diagram.json")); err == nil {
+		ld.Diagram = diag
+	}
+
 	return ld, nil
 }
 
@@ -576,24 +789,7 @@ func (a *App) SelectCustomLinDir() (LinDirData, error) {
 		return LinDirData{}, err
 	}
 
-	// Create linearization directory data structure
-	ld := LinDirData{Dir: linDir}
-
-	// Attempt to load results and diagram
-	if results, err := LoadResults(linDir); err == nil {
-		a.Project.Results = results
-		ld.Results = results.ForApp()
-	} else {
-		fmt.Println(err)
-	}
-	if diag, err := diagram.Load(filepath.Join(linDir, "diagram.json")); err == nil {
-		a.Project.Diagram = diag
-		ld.Diagram = diag
-	} else {
-		fmt.Println(err)
-	}
-
-	return ld, nil
+	return a.loadLinDir(linDir)
 }
 
 func (a *App) FetchResults() (*Results, error) {
@@ -611,30 +807,60 @@ func (a *App) FetchResults() (*Results, error) {
 	return a.Project.Results.ForApp(), nil
 }
 
-func (a *App) ProcessLinDir(linDir string) (*Results, error) {
+// ProcessLinDir finds the linearization files in linDir using the discovery
+// options, processes them into results and saves the results.
+func (a *App) ProcessLinDir(linDir string, dopts lin.DiscoverOptions) (*Results, error) {
+
+	// Cancel any processing in progress and create a cancelable context
+	ProcessCancel(fmt.Errorf("new processing started"))
+	ctx, cancelFunc := context.WithCancelCause(a.ctx)
+	ProcessCancel = cancelFunc
+	defer cancelFunc(nil)
+
+	// Process OPs concurrently using the number of CPUs selected for evaluation
+	// and send status to frontend as files are read and OPs are analyzed
+	opts := lin.NewProcessOptions()
+	if a.Project.Evaluate != nil {
+		opts.NumCPUs = a.Project.Evaluate.NumCPUs
+	}
+	opts.Progress = func(p lin.ProcessProgress) {
+		SendProcessStatus(a.ctx, ProcessStatus{
+			Stage:        p.Stage,
+			OP:           filepath.Base(p.RootPath),
+			NumFiles:     p.NumFiles,
+			NumFilesRead: p.NumFilesRead,
+			NumOPs:       p.NumOPs,
+			NumOPsDone:   p.NumOPsDone,
+		})
+	}
 
 	// Process case directory to get results
-	results, err := ProcessCaseDir(linDir)
+	results, err := ProcessCaseDir(ctx, linDir, dopts, opts)
 	if err != nil {
+		SendProcessStatus(a.ctx, ProcessStatus{Stage: "Error", Error: err.Error()})
 		return nil, err
 	}
 
-	// Add results to project
-	a.Project.Results = results
-
-	// Save project
-	if _, err := a.Project.Save(); err != nil {
+	// Save results file and results JSON file with matching keys. This is
+	// done before adding results to the project, so saving the project
+	// finds the results JSON file unchanged and doesn't write it again.
+	if err := results.Save(linDir); err != nil {
 		return nil, err
 	}
 
-	// Save results
-	if err := results.Save(linDir); err != nil {
+	// Add results to project and save project
+	a.Project.Results = results
+	if _, err := a.Project.Save(); err != nil {
 		return nil, err
 	}
 
 	return a.Project.Results.ForApp(), nil
 }
 
+func (a *App) CancelProcess() {
+	ProcessCancel(fmt.Errorf("processing canceled"))
+}
+
 //------------------------------------------------------------------------------
 // Diagram
 //------------------------------------------------------------------------------
@@ -646,12 +872,24 @@ func (a *App) GenerateDiagram(opts diagram.Options) (*diagram.Diagram, error) {
 		return nil, fmt.Errorf("load results before generating diagram")
 	}
 
+	// Read modes of all operating points from results file
+	if err := a.Project.Results.LoadLinOPs(); err != nil {
+		return nil, err
+	}
+
 	// Generate diagram with given options
 	diag, err := diagram.New(a.Project.Results.LinOPs, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	// Carry over line styling from the previous diagram
+	if a.Project.Diagram != nil {
+		if err := diag.CopyStyle(a.Project.Diagram, a.Project.Results.LinOPs); err != nil {
+			return nil, err
+		}
+	}
+
 	// Save diagram in project
 	a.Project.Diagram = diag
 
@@ -666,6 +904,11 @@ func (a *App) GenerateDiagram(opts diagram.Options) (*diagram.Diagram, error) {
 // UpdateDiagram saves the diagram to file
 func (a *App) UpdateDiagram(diag *diagram.Diagram) error {
 
+	// Check that lines are valid
+	if err := diag.Validate(); err != nil {
+		return fmt.Errorf("invalid diagram: %w", err)
+	}
+
 	// Update analysis in the project
 	a.Project.Diagram = diag
 
@@ -677,6 +920,188 @@ func (a *App) UpdateDiagram(diag *diagram.Diagram) error {
 	return nil
 }
 
+// MergeDiagramLines moves the points of line 2 into line 1
+func (a *App) MergeDiagramLines(lineID1, lineID2 int) (*diagram.Diagram, error) {
+	return a.editDiagram(func(d *diagram.Diagram) error {
+		return d.MergeLines(lineID1, lineID2)
+	})
+}
+
+// SplitDiagramLine moves the points of the line at and after the operating
+// point into a new line
+func (a *App) SplitDiagramLine(lineID, opID int) (*diagram.Diagram, error) {
+	return a.editDiagram(func(d *diagram.Diagram) error {
+		_, err := d.SplitLine(lineID, opID)
+		return err
+	})
+}
+
+// MoveDiagramPoint moves the point of the mode at the operating point to the line
+func (a *App) MoveDiagramPoint(opID, modeID, lineID int) (*diagram.Diagram, error) {
+	return a.editDiagram(func(d *diagram.Diagram) error {
+		return d.MovePoint(opID, modeID, lineID)
+	})
+}
+
+// SwapDiagramLines exchanges the points of the lines at and after the operating point
+func (a *App) SwapDiagramLines(lineID1, lineID2, opID int) (*diagram.Diagram, error) {
+	return a.editDiagram(func(d *diagram.Diagram) error {
+		return d.SwapLines(lineID1, lineID2, opID)
+	})
+}
+
+// editDiagram applies the edit to the diagram, updates the link scores from
+// the loaded results, and saves the project
+func (a *App) editDiagram(edit func(d *diagram.Diagram) error) (*diagram.Diagram, error) {
+
+	// Check that diagram has been loaded
+	if a.Project.Diagram == nil {
+		return nil, fmt.Errorf("generate diagram before editing lines")
+	}
+
+	// Apply edit
+	if err := edit(a.Project.Diagram); err != nil {
+		return nil, err
+	}
+
+	// Update link scores if linearization results are loaded
+	if a.Project.Results != nil && len(a.Project.Results.LinOPs) > 0 {
+		if err := a.Project.Results.LoadLinOPs(); err != nil {
+			return nil, err
+		}
+		if err := a.Project.Diagram.Relink(a.Project.Results.LinOPs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save project
+	if _, err := a.Project.Save(); err != nil {
+		return nil, err
+	}
+
+	return a.Project.Diagram, nil
+}
+
+// SaveStyleTemplateDialog opens a dialog to select where to save the line
+// styling of the current diagram as a template
+func (a *App) SaveStyleTemplateDialog() error {
+
+	// Check that diagram has been loaded
+	if a.Project.Diagram == nil {
+		return fmt.Errorf("generate diagram before saving style template")
+	}
+
+	// Open dialog so user can select the file
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Save Style Template As",
+		DefaultFilename: "diagram_style.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Style Templates (*.json)", Pattern: "*.json"},
+		},
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return fmt.Errorf("error selecting style template file: %w", err)
+	}
+
+	// If path not selected, return
+	if path == "" {
+		return nil
+	}
+
+	// Convert template into JSON
+	bs, err := json.MarshalIndent(a.Project.Diagram.StyleTemplate(), "", "\t")
+	if err != nil {
+		return fmt.Errorf("error encoding style template: %w", err)
+	}
+
+	return writeFileAtomic(path, bs)
+}
+
+// ApplyStyleTemplateDialog opens a dialog to select a style template and
+// applies it to the lines of the current diagram with matching frequencies
+func (a *App) ApplyStyleTemplateDialog() (*diagram.Diagram, error) {
+
+	// Check that diagram has been loaded
+	if a.Project.Diagram == nil {
+		return nil, fmt.Errorf("generate diagram before applying style template")
+	}
+
+	// Open dialog so user can select the file
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Open Style Template",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Style Templates (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error selecting style template file: %w", err)
+	}
+
+	// If path not selected, return current diagram
+	if path == "" {
+		return a.Project.Diagram, nil
+	}
+
+	// Read template
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading style template '%s': %w", path, err)
+	}
+	tmpl := diagram.StyleTemplate{}
+	if err := json.Unmarshal(bs, &tmpl); err != nil {
+		return nil, fmt.Errorf("error parsing style template '%s': %w", path, err)
+	}
+
+	// Apply template to diagram
+	if _, err := a.Project.Diagram.ApplyStyleTemplate(tmpl, diagram.MinStyleMatchScore); err != nil {
+		return nil, err
+	}
+
+	// Save project
+	if _, err := a.Project.Save(); err != nil {
+		return nil, err
+	}
+
+	return a.Project.Diagram, nil
+}
+
+// CompareSource identifies the diagram to compare: the diagram of a run of
+// the case or, if RunID is zero, the diagram of the latest results.
+type CompareSource struct {
+	CaseID int `json:"CaseID"`
+	RunID  int `json:"RunID"`
+}
+
+// CompareDiagrams matches the lines of two saved diagrams and returns the
+// frequency and damping differences and an overlay for plotting.
+func (a *App) CompareDiagrams(src1, src2 CompareSource, opts diagram.CompareOptions) (*diagram.Comparison, error) {
+
+	// Load results and diagram for each source
+	diags := [2]*diagram.Diagram{}
+	linOPs := [2][]lin.LinOP{}
+	for i, src := range []CompareSource{src1, src2} {
+		linDir, err := a.Project.RunResultsDir(src.CaseID, src.RunID)
+		if err != nil {
+			return nil, err
+		}
+		results, err := LoadResults(linDir)
+		if err != nil {
+			return nil, fmt.Errorf("error loading results from '%s': %w", linDir, err)
+		}
+		if err := results.LoadLinOPs(); err != nil {
+			return nil, fmt.Errorf("error loading results from '%s': %w", linDir, err)
+		}
+		diags[i], err = LoadDiagram(filepath.Join(linDir, "diagram.json"))
+		if err != nil {
+			return nil, fmt.Errorf("error loading diagram from '%s', generate diagram before comparing: %w", linDir, err)
+		}
+		linOPs[i] = results.LinOPs
+	}
+
+	return diagram.Compare(diags[0], diags[1], linOPs[0], linOPs[1], opts)
+}
+
 //------------------------------------------------------------------------------
 // Visualization
 //------------------------------------------------------------------------------
@@ -693,13 +1118,14 @@ func (a *App) GetModeViz(opID int, modeID int, scale float32) (*viz.ModeData, er
 		return nil, fmt.Errorf("load results before generating visualization")
 	}
 
-	// If operating point index is not valid, return error
-	if opID < 0 || opID >= len(a.Project.Results.LinOPs) {
-		return nil, fmt.Errorf("invalid operating point ID: %d", opID)
+	// Get operating point, reading it from the results file if necessary
+	linOP, err := a.Project.Results.LinOP(opID)
+	if err != nil {
+		return nil, err
 	}
 
 	// If mode index is not valid, return error
-	if modeID < 0 || modeID >= len(a.Project.Results.LinOPs[opID].Modes) {
+	if modeID < 0 || modeID >= len(linOP.Modes) {
 		return nil, fmt.Errorf("invalid mode ID (%d) for operating point (%d)", modeID, opID)
 	}
 
@@ -707,8 +1133,7 @@ func (a *App) GetModeViz(opID int, modeID int, scale float32) (*viz.ModeData, er
 	opts := viz.Options{Scale: scale}
 
 	// Generate mode visualization data
-	modeData, err := opts.GenerateModeData(a.Project.Evaluate.ExecPath,
-		&a.Project.Results.LinOPs[opID], []int{modeID})
+	modeData, err := opts.GenerateModeData(a.Project.Evaluate.ExecPath, linOP, []int{modeID})
 	if err != nil {
 		return nil, err
 	}
@@ -724,7 +1149,129 @@ func (a *App) GetModeViz(opID int, modeID int, scale float32) (*viz.ModeData, er
 // Export Data
 //------------------------------------------------------------------------------
 
-func (a *App) ExportDiagramDataJSON(diag diagram.Diagram) error {
+// ExportDiagramImageDialog opens a dialog to select where to save an SVG or
+// PNG image of the current diagram and renders it
+func (a *App) ExportDiagramImageDialog(opts diagram.RenderOptions) error {
+
+	// Check that diagram has been loaded
+	if a.Project.Diagram == nil {
+		return fmt.Errorf("generate diagram before exporting image")
+	}
+
+	// Open dialog so user can select the file
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Diagram Image",
+		DefaultFilename: "campbell_diagram.svg",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "SVG Images (*.svg)", Pattern: "*.svg"},
+			{DisplayName: "PNG Images (*.png)", Pattern: "*.png"},
+		},
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return fmt.Errorf("error selecting image file: %w", err)
+	}
+
+	// If path not selected, return
+	if path == "" {
+		return nil
+	}
+
+	return a.Project.Diagram.RenderFile(path, opts)
+}
+
+// ExportTablesDialog opens a dialog to select where to export the metadata,
+// diagram, and mode tables of the loaded results. The format is "xlsx" for a
+// workbook with a sheet per table or "csv" for a CSV file per table in the
+// selected directory. Diagram values are converted to the units.
+func (a *App) ExportTablesDialog(format string, units diagram.Units) error {
+
+	// Build tables
+	tables, err := a.Project.ExportTables(units)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+
+	case "xlsx":
+
+		// Open dialog so user can select the file
+		path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export Tables",
+			DefaultFilename: "campbell_diagram.xlsx",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "Excel Workbooks (*.xlsx)", Pattern: "*.xlsx"},
+			},
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			return fmt.Errorf("error selecting workbook file: %w", err)
+		}
+		if path == "" {
+			return nil
+		}
+		return WriteTablesXLSX(path, tables)
+
+	case "csv":
+
+		// Open dialog so user can select the directory
+		dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+			Title:                "Select Export Directory",
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			return fmt.Errorf("error selecting export directory: %w", err)
+		}
+		if dir == "" {
+			return nil
+		}
+		return WriteTablesCSV(dir, tables)
+
+	default:
+		return fmt.Errorf("unknown export format '%s'", format)
+	}
+}
+
+// ExportModesCSVDialog opens a dialog to select a directory and writes the
+// modes of each operating point, including eigenvectors, to a CSV file.
+func (a *App) ExportModesCSVDialog() error {
+
+	// Check that results have been loaded
+	if a.Project.Results == nil {
+		return fmt.Errorf("load results before exporting modes")
+	}
+
+	// Open dialog so user can select the directory
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Select Export Directory",
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return fmt.Errorf("error selecting export directory: %w", err)
+	}
+	if dir == "" {
+		return nil
+	}
+
+	return a.Project.Results.ExportModesCSV(dir)
+}
+
+// DiagramSeries returns the diagram lines with frequency and damping values
+// converted to the units
+func (a *App) DiagramSeries(diag diagram.Diagram, units diagram.Units) (*diagram.Series, error) {
+	return diag.Series(units)
+}
+
+// ExportDiagramDataJSON opens a dialog to select where to save the diagram
+// and writes it with the line values converted to the units
+func (a *App) ExportDiagramDataJSON(diag diagram.Diagram, units diagram.Units) error {
+
+	// Convert line values to units
+	series, err := diag.Series(units)
+	if err != nil {
+		return err
+	}
 
 	// Open dialog so user can select the file
 	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
@@ -741,8 +1288,11 @@ func (a *App) ExportDiagramDataJSON(diag diagram.Diagram) error {
 		return fmt.Errorf("error creating project directory '%s': %w", path, err)
 	}
 
-	// Convert config into JSON
-	bs, err := json.MarshalIndent(diag, "", "\t")
+	// Convert diagram and series into JSON
+	bs, err := json.MarshalIndent(struct {
+		*diagram.Diagram
+		Series *diagram.Series `json:"Series"`
+	}{&diag, series}, "", "\t")
 	if err != nil {
 		return fmt.Errorf("error marshalling data: %w", err)
 	}
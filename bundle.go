@@ -0,0 +1,478 @@
+package main
+
+import (
+	"acdc/lin"
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BundleVersion is the version of the bundle archive layout written by
+// Project.ExportBundle. Bundles with a newer version can't be imported.
+const BundleVersion = 1
+
+// bundleManifestName is the name of the manifest file in the bundle archive
+const bundleManifestName = "bundle.json"
+
+// BundleOptions specifies what is included in an exported bundle
+type BundleOptions struct {
+	CaseIDs         []int `json:"CaseIDs"`
+	IncludeLinFiles bool  `json:"IncludeLinFiles"`
+}
+
+// BundleManifest describes the contents of a bundle archive. All paths
+// are slash separated and relative to the root of the archive.
+type BundleManifest struct {
+	Version     int          `json:"Version"`
+	Date        string       `json:"Date"`
+	ProjectFile string       `json:"ProjectFile"`
+	ModelDir    string       `json:"ModelDir"`
+	Cases       []BundleCase `json:"Cases"`
+}
+
+type BundleCase struct {
+	ID    int      `json:"ID"`
+	Name  string   `json:"Name"`
+	Dir   string   `json:"Dir"`
+	Files []string `json:"Files"`
+}
+
+// ExportBundle writes the project, the model files, and the results of the
+// selected cases to a zip archive at the given path. Paths stored in the
+// archive are relative so the bundle can be imported on another machine.
+func (p *Project) ExportBundle(bundlePath string, opts BundleOptions) error {
+
+	if p == nil {
+		return fmt.Errorf("project not loaded")
+	}
+
+	// Create temporary file in destination directory so a failed export
+	// doesn't leave a partial bundle at the given path
+	f, err := createTempFile(bundlePath)
+	if err != nil {
+		return fmt.Errorf("error creating bundle '%s': %w", bundlePath, err)
+	}
+	tmpPath := f.Name()
+
+	// Write bundle, then replace destination file with temporary file
+	err = p.writeBundle(f, opts)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing bundle '%s': %w", bundlePath, closeErr)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, bundlePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// writeBundle writes the bundle zip archive to w.
+func (p *Project) writeBundle(w io.Writer, opts BundleOptions) error {
+
+	// Create zip writer
+	zw := zip.NewWriter(w)
+	defer zw.Close()
+
+	// Initialize manifest
+	rootName := filepath.Base(p.RootPath())
+	manifest := BundleManifest{
+		Version:     BundleVersion,
+		Date:        time.Now().Format(time.RFC850),
+		ProjectFile: rootName + ".json",
+		Cases:       []BundleCase{},
+	}
+
+	//--------------------------------------------------------------------------
+	// Project
+	//--------------------------------------------------------------------------
+
+	// Create temporary project without path so it isn't tied to this machine
+	pSave := Project{
+		Info:     Info{Date: p.Info.Date, SchemaVersion: projectSchema.Version()},
+		Model:    p.Model,
+		Analysis: p.Analysis,
+		Evaluate: p.Evaluate,
+		Runs:     p.Runs,
+	}
+
+	// Write project file to bundle
+	bs, err := json.MarshalIndent(pSave, "", "\t")
+	if err != nil {
+		return fmt.Errorf("error encoding project: %w", err)
+	}
+	if err := zipWriteBytes(zw, manifest.ProjectFile, bs); err != nil {
+		return err
+	}
+
+	//--------------------------------------------------------------------------
+	// Model
+	//--------------------------------------------------------------------------
+
+	// If model files have been imported, write them to a temporary directory
+	// and copy them into the bundle
+	if p.Model != nil && p.Model.Files != nil {
+
+		tmpDir, err := os.MkdirTemp("", "acdc-bundle-")
+		if err != nil {
+			return fmt.Errorf("error creating temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		if err := p.Model.Files.Write(tmpDir, ""); err != nil {
+			return fmt.Errorf("error writing model files: %w", err)
+		}
+
+		manifest.ModelDir = "model"
+		err = filepath.WalkDir(tmpDir, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			relPath, err := filepath.Rel(tmpDir, filePath)
+			if err != nil {
+				return err
+			}
+			return zipWriteFile(zw, path.Join(manifest.ModelDir, filepath.ToSlash(relPath)), filePath)
+		})
+		if err != nil {
+			return fmt.Errorf("error adding model files to bundle: %w", err)
+		}
+	}
+
+	//--------------------------------------------------------------------------
+	// Case results
+	//--------------------------------------------------------------------------
+
+	for _, caseID := range opts.CaseIDs {
+
+		// Find case
+		c, err := p.Case(caseID)
+		if err != nil {
+			return err
+		}
+
+		// Loop through run and case directories that contain results
+		numDirs := 0
+		for _, resultsDir := range p.ResultsDirs(c.ID) {
+
+			if _, err := os.Stat(filepath.Join(resultsDir, "results.json")); err != nil {
+				continue
+			}
+
+			// Get results directory relative to the project root path
+			relDir, err := filepath.Rel(p.RootPath(), resultsDir)
+			if err != nil {
+				return err
+			}
+
+			// Create bundle case
+			bc := BundleCase{
+				ID:   c.ID,
+				Name: c.Name,
+				Dir:  path.Join(rootName, filepath.ToSlash(relDir)),
+			}
+
+			// Write results to bundle
+			if bc.Files, err = zipWriteResults(zw, resultsDir, bc.Dir, opts.IncludeLinFiles); err != nil {
+				return fmt.Errorf("error adding results for Case %d '%s': %w", c.ID, c.Name, err)
+			}
+
+			manifest.Cases = append(manifest.Cases, bc)
+			numDirs++
+		}
+
+		// If no results were found for case, return error
+		if numDirs == 0 {
+			return fmt.Errorf("no results found for Case %d '%s'", c.ID, c.Name)
+		}
+	}
+
+	//--------------------------------------------------------------------------
+	// Manifest
+	//--------------------------------------------------------------------------
+
+	bs, err = json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return fmt.Errorf("error encoding bundle manifest: %w", err)
+	}
+	if err := zipWriteBytes(zw, bundleManifestName, bs); err != nil {
+		return err
+	}
+
+	// Close zip writer to flush the central directory
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error writing bundle: %w", err)
+	}
+
+	return nil
+}
+
+// ImportBundle extracts the bundle archive into the destination directory,
+// rebases all project and result paths to the new location, and returns the
+// loaded project. Existing files in the destination directory are never
+// overwritten and nothing is left behind if the import fails.
+func ImportBundle(bundlePath, dstDir string) (p *Project, err error) {
+
+	// Open bundle
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening bundle '%s': %w", bundlePath, err)
+	}
+	defer zr.Close()
+
+	// Read manifest
+	manifest := BundleManifest{}
+	mf, err := zr.Open(bundleManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("bundle '%s' has no manifest: %w", bundlePath, err)
+	}
+	err = json.NewDecoder(mf).Decode(&manifest)
+	mf.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing bundle manifest: %w", err)
+	}
+	if manifest.Version > BundleVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than supported version %d",
+			manifest.Version, BundleVersion)
+	}
+
+	// Extract files into a temporary directory in the destination directory
+	if err := os.MkdirAll(dstDir, 0777); err != nil {
+		return nil, fmt.Errorf("error creating directory '%s': %w", dstDir, err)
+	}
+	tmpDir, err := os.MkdirTemp(dstDir, ".bundle.tmp*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	for _, zf := range zr.File {
+		if zf.Name == bundleManifestName || zf.FileInfo().IsDir() {
+			continue
+		}
+		if err := zipExtractFile(zf, tmpDir); err != nil {
+			return nil, err
+		}
+	}
+
+	// Don't overwrite an existing project, model, or case directories
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading extracted bundle: %w", err)
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(dstDir, e.Name())); err == nil {
+			return nil, fmt.Errorf("'%s' already exists", filepath.Join(dstDir, e.Name()))
+		}
+	}
+
+	// Move extracted files into place, remove them if the import fails
+	moved := []string{}
+	defer func() {
+		if err != nil {
+			for _, movedPath := range moved {
+				os.RemoveAll(movedPath)
+			}
+		}
+	}()
+	for _, e := range entries {
+		dstPath := filepath.Join(dstDir, e.Name())
+		if err := os.Rename(filepath.Join(tmpDir, e.Name()), dstPath); err != nil {
+			return nil, fmt.Errorf("error moving '%s' into place: %w", dstPath, err)
+		}
+		moved = append(moved, dstPath)
+	}
+
+	// Load project
+	projectPath := filepath.Join(dstDir, filepath.FromSlash(manifest.ProjectFile))
+	p, err = LoadProject(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Point model paths at the model files extracted from the bundle,
+	// relative to the project directory
+	if manifest.ModelDir != "" && p.Model != nil {
+		paths := []string{}
+		for _, zf := range zr.File {
+			name, ok := strings.CutPrefix(zf.Name, manifest.ModelDir+"/")
+			if ok && !zf.FileInfo().IsDir() {
+				paths = append(paths, filepath.Join(manifest.ModelDir, filepath.FromSlash(name)))
+			}
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			return filepath.Base(paths[i]) < filepath.Base(paths[j])
+		})
+		p.Model.ImportedPaths = paths
+	}
+
+	// Rebase case results to extracted case directories
+	for _, bc := range manifest.Cases {
+		caseDir := filepath.Join(dstDir, filepath.FromSlash(bc.Dir))
+		results, err := LoadResults(caseDir)
+		if err != nil {
+			return nil, fmt.Errorf("error loading results for Case %d '%s': %w", bc.ID, bc.Name, err)
+		}
+		results.Rebase(caseDir)
+		if err := results.Save(caseDir); err != nil {
+			return nil, fmt.Errorf("error saving results for Case %d '%s': %w", bc.ID, bc.Name, err)
+		}
+	}
+
+	// Remove runs whose results weren't included in the bundle
+	runs := []Run{}
+	for _, r := range p.Runs {
+		if _, err := os.Stat(p.RunDir(&r)); err == nil {
+			runs = append(runs, r)
+		}
+	}
+	p.Runs = runs
+
+	// Executable path is specific to the exporting machine, invalidate if not found
+	if p.Evaluate != nil && p.Evaluate.ExecPath != "" {
+		if _, err := exec.LookPath(p.Evaluate.ExecPath); err != nil {
+			p.Evaluate.ExecValid = false
+		}
+	}
+
+	// Save project at new location
+	if _, err := p.Save(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// zipWriteResults writes the results in resultsDir to the archive directory
+// and returns the names of the files that were written. Result paths are
+// made relative so they can be rebased when the bundle is imported.
+func zipWriteResults(zw *zip.Writer, resultsDir, zipDir string, includeLinFiles bool) ([]string, error) {
+
+	// Load results
+	results, err := LoadResults(resultsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make result paths relative to the results directory and write to bundle
+	results.Rebase("")
+	bs, err := json.MarshalIndent(results, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err := zipWriteBytes(zw, path.Join(zipDir, "results.json"), bs); err != nil {
+		return nil, err
+	}
+
+	// Collect names of files to copy from results directory
+	fileNames := []string{}
+	if _, err := os.Stat(filepath.Join(resultsDir, "diagram.json")); err == nil {
+		fileNames = append(fileNames, "diagram.json")
+	}
+	if results.file != nil {
+		fileNames = append(fileNames, resultsFileName)
+	}
+	for _, linOP := range results.LinOPs {
+		if results.file == nil {
+			fileNames = append(fileNames, linOP.RootPath+"_mbc.json", linOP.RootPath+"_modes.csv")
+		}
+		if includeLinFiles {
+			for _, filePath := range linOP.FilePaths {
+				// Include the whole archive for files inside an archive
+				if archivePath, _, ok := lin.SplitArchivePath(filepath.Join(resultsDir, filePath)); ok {
+					filePath, _ = filepath.Rel(resultsDir, archivePath)
+				}
+				if !slices.Contains(fileNames, filePath) {
+					fileNames = append(fileNames, filePath)
+				}
+			}
+		}
+	}
+
+	// Copy files into bundle
+	for _, fileName := range fileNames {
+		err := zipWriteFile(zw, path.Join(zipDir, filepath.ToSlash(fileName)), filepath.Join(resultsDir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("error adding '%s' to bundle: %w", fileName, err)
+		}
+	}
+
+	return append([]string{"results.json"}, fileNames...), nil
+}
+
+// zipWriteBytes writes the bytes to a file with the given name in the archive
+func zipWriteBytes(zw *zip.Writer, name string, bs []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating '%s' in bundle: %w", name, err)
+	}
+	if _, err := w.Write(bs); err != nil {
+		return fmt.Errorf("error writing '%s' to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// zipWriteFile copies the file at srcPath to a file with the given name in the archive
+func zipWriteFile(zw *zip.Writer, name, srcPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating '%s' in bundle: %w", name, err)
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("error writing '%s' to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// zipExtractFile extracts the archive file into the destination directory.
+// Files which would be written outside the directory are rejected.
+func zipExtractFile(zf *zip.File, dstDir string) error {
+
+	// Build destination path and check that it is inside the destination directory
+	dstPath := filepath.Join(dstDir, filepath.FromSlash(zf.Name))
+	if !strings.HasPrefix(dstPath, filepath.Clean(dstDir)+string(os.PathSeparator)) {
+		return fmt.Errorf("invalid file path in bundle: '%s'", zf.Name)
+	}
+
+	// Create parent directory
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0777); err != nil {
+		return fmt.Errorf("error creating directory '%s': %w", filepath.Dir(dstPath), err)
+	}
+
+	// Copy file contents
+	src, err := zf.Open()
+	if err != nil {
+		return fmt.Errorf("error reading '%s' from bundle: %w", zf.Name, err)
+	}
+	defer src.Close()
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("error creating '%s': %w", dstPath, err)
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error extracting '%s': %w", zf.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"acdc/lin"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	// Create project with model and analysis
+	project := NewProject()
+	project.Info.Path = filepath.Join(srcDir, "turbine.json")
+	model, err := ParseModelFiles("testdata/fio-v3.5.x/NREL_5MW.fst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	project.Model = model
+	project.Analysis = NewAnalysis()
+
+	// Copy linearization file into case directory and process results
+	caseDir := project.CaseDir(1)
+	if err := os.MkdirAll(caseDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := os.ReadFile("lin/testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(caseDir, "Tow.1.lin"), bs, 0777); err != nil {
+		t.Fatal(err)
+	}
+	results, err := ProcessCaseDir(context.Background(), caseDir, lin.NewDiscoverOptions(), lin.NewProcessOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := results.Save(caseDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Export bundle
+	bundlePath := filepath.Join(srcDir, "turbine.zip")
+	err = project.ExportBundle(bundlePath, BundleOptions{CaseIDs: []int{1}, IncludeLinFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check that bundled project has the current schema version so it isn't
+	// migrated again on import
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf, err := zr.Open("turbine.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundled := Project{}
+	err = json.NewDecoder(zf).Decode(&bundled)
+	zf.Close()
+	zr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := bundled.Info.SchemaVersion, projectSchema.Version(); act != exp {
+		t.Fatalf("bundled.Info.SchemaVersion = %v, expected %v", act, exp)
+	}
+
+	// Importing over an existing case directory fails and leaves no files
+	conflictDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(conflictDir, "turbine", "Case01"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportBundle(bundlePath, conflictDir); err == nil {
+		t.Fatal("expected error importing over existing case directory")
+	}
+	if entries, _ := os.ReadDir(conflictDir); len(entries) != 1 {
+		t.Fatalf("failed import left files in destination: %v", entries)
+	}
+
+	// Import bundle
+	imported, err := ImportBundle(bundlePath, dstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := imported.Info.Path, filepath.Join(dstDir, "turbine.json"); act != exp {
+		t.Fatalf("imported.Info.Path = %v, expected %v", act, exp)
+	}
+	if act, exp := imported.Info.SchemaVersion, project.Info.SchemaVersion; act != exp {
+		t.Fatalf("imported.Info.SchemaVersion = %v, expected %v", act, exp)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "model", "NREL_5MW.fst")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check that model paths point to the extracted model files
+	if !slices.Contains(imported.Model.ImportedPaths, filepath.Join("model", "NREL_5MW.fst")) {
+		t.Fatalf("imported.Model.ImportedPaths = %v, expected extracted model files", imported.Model.ImportedPaths)
+	}
+	for _, path := range imported.Model.ImportedPaths {
+		if _, err := os.Stat(filepath.Join(dstDir, path)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Check that results were rebased to the new case directory
+	newCaseDir := imported.CaseDir(1)
+	res, err := LoadResults(newCaseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := res.LinDir, newCaseDir; act != exp {
+		t.Fatalf("res.LinDir = %v, expected %v", act, exp)
+	}
+	if act, exp := len(res.LinOPs), 1; act != exp {
+		t.Fatalf("len(res.LinOPs) = %v, expected %v", act, exp)
+	}
+	for _, f := range res.LinOPs[0].FilePaths {
+		if !strings.HasPrefix(f, newCaseDir) {
+			t.Fatalf("file path '%s' not in '%s'", f, newCaseDir)
+		}
+		if _, err := os.Stat(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linOP, err := res.LinOP(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(linOP.Modes), len(results.LinOPs[0].Modes); act != exp {
+		t.Fatalf("len(linOP.Modes) = %v, expected %v", act, exp)
+	}
+
+	// Importing again should fail as the project exists
+	if _, err := ImportBundle(bundlePath, dstDir); err == nil {
+		t.Fatal("expected error importing over existing project")
+	}
+
+	// Failed export leaves the existing bundle unchanged and no temporary files
+	before, err := os.ReadFile(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := project.ExportBundle(bundlePath, BundleOptions{CaseIDs: []int{99}}); err == nil {
+		t.Fatal("expected error exporting missing case")
+	}
+	after, err := os.ReadFile(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("failed export modified existing bundle")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(srcDir, ".turbine.zip.tmp*")); len(matches) != 0 {
+		t.Fatalf("temporary files not removed: %v", matches)
+	}
+}
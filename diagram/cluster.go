@@ -4,15 +4,21 @@ import (
 	"acdc/lin"
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"sort"
 
 	"github.com/mkmik/argsort"
-	"github.com/parallelo-ai/kmeans"
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
-func clusterModes(OPs []lin.LinOP, modeSets []*ModeSet) error {
+// clusterModes refines overlapping mode sets with spectral clustering.
+// The seed initializes the random number generator used by k-means so the
+// same results and seed always produce the same mode sets.
+func clusterModes(OPs []lin.LinOP, modeSets []*ModeSet, seed int64) error {
+
+	// Create random number generator shared by all groups
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
 
 	// Find groups of potentially overlapping mode sets
 	modeSetGroups := [][]*ModeSet{{}}
@@ -58,7 +64,7 @@ func clusterModes(OPs []lin.LinOP, modeSets []*ModeSet) error {
 	// perform spectral clustering to identify shared modes
 	for _, group := range modeSetGroups {
 		if len(group) > 1 {
-			if err := spectralClustering(group); err != nil {
+			if err := spectralClustering(group, rng); err != nil {
 				return err
 			}
 		}
@@ -67,7 +73,7 @@ func clusterModes(OPs []lin.LinOP, modeSets []*ModeSet) error {
 	return nil
 }
 
-func spectralClustering(modeSets []*ModeSet) error {
+func spectralClustering(modeSets []*ModeSet, rng *rand.Rand) error {
 
 	// Collect all modes in mode sets
 	modes := []*lin.Mode{}
@@ -120,20 +126,14 @@ func spectralClustering(modeSets []*ModeSet) error {
 	numClusters := len(modeSets)
 	numDims := min(int(math.Ceil(1*float64(numClusters))), N)
 
-	d := make(kmeans.Observations, N)
+	d := make([][]float64, N)
 	for i := 0; i < N; i++ {
 		row := make([]float64, numDims)
 		for j, ind := range indices[:numDims] {
 			row[j] = real(eigenVectors.At(i, ind))
 		}
 		floats.Scale(1/floats.Norm(row, 2), row)
-		d[i] = Observation(row)
-	}
-
-	// Create KMeans object with options
-	km, err := kmeans.NewKmeansWithOptions(0.001, 1000)
-	if err != nil {
-		return err
+		d[i] = row
 	}
 
 	clusterModesMap := map[int][]*lin.Mode{}
@@ -143,16 +143,12 @@ func spectralClustering(modeSets []*ModeSet) error {
 	for i := 0; i < 1000; i++ {
 
 		// Partition the data points
-		clusters, err := km.Partition(d, numClusters, 0)
-		if err != nil {
-			return err
-		}
+		clusters := kMeans(d, numClusters, rng, 1000)
 
 		// Get cluster number for each mode (starts at 0)
 		localClusterModesMap := map[int][]*lin.Mode{}
 		localModeClusterMap := map[*lin.Mode]int{}
-		for i, obs := range d {
-			c := clusters.Nearest(obs)
+		for i, c := range clusters {
 			if _, ok := localClusterModesMap[c]; !ok {
 				localClusterModesMap[c] = []*lin.Mode{}
 			}
@@ -188,19 +184,15 @@ func spectralClustering(modeSets []*ModeSet) error {
 	}
 
 	// Build cost matrix for determining which mode set goes with each cluster
-	C := make([][]int, len(modeSets))
+	C := NewFloatMatrix(len(modeSets), numClusters, 0)
 	for i := range C {
-		C[i] = make([]int, numClusters)
 		for _, m := range modeSets[i].Modes {
-			C[i][modeClusterMap[m]]++
-		}
-		for j := range C[i] {
-			C[i][j] = N - 1 - C[i][j]
+			C[i][modeClusterMap[m]]--
 		}
 	}
 
 	// Pair mode set with cluster which have the most overlapping modes
-	modeSetClusterPairs, err := MinCostAssignment(C)
+	modeSetClusterPairs, err := LinearAssignment(C)
 	if err != nil {
 		return err
 	}
@@ -238,15 +230,3 @@ func spectralClustering(modeSets []*ModeSet) error {
 
 	return nil
 }
-
-type Observation []float64
-
-func (obs Observation) Coordinates() kmeans.Coordinates {
-	return kmeans.Coordinates(obs)
-}
-
-func (obs Observation) Distance(point kmeans.Coordinates) float64 {
-	diff := make([]float64, len(obs))
-	floats.SubTo(diff, obs, []float64(point))
-	return floats.Norm(diff, 2)
-}
@@ -0,0 +1,308 @@
+package diagram
+
+import (
+	"acdc/lin"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// CompareOptions specifies how lines are matched between two diagrams
+type CompareOptions struct {
+	OPTolerance float64 `json:"OPTolerance"` // Max rotor speed (RPM) and wind speed (m/s) difference between matching OPs
+	MinScore    float64 `json:"MinScore"`    // Lines with a match score at or below this value are unmatched
+}
+
+// Comparison contains the result of matching the lines of two diagrams.
+// Index 0 of each pair refers to the first diagram, index 1 to the second.
+type Comparison struct {
+	UsedMAC   bool        `json:"UsedMAC"` // Mode shapes were used for matching, otherwise frequency only
+	OPs       []OPPair    `json:"OPs"`
+	Matches   []LineMatch `json:"Matches"`
+	Unmatched [2][]int    `json:"Unmatched"` // IDs of lines without a match in each diagram
+	Overlay   Overlay     `json:"Overlay"`
+}
+
+// OPPair is an operating point which exists in both diagrams
+type OPPair struct {
+	OP        [2]int     `json:"OP"`
+	RotSpeed  [2]float32 `json:"RotSpeed"`
+	WindSpeed [2]float32 `json:"WindSpeed"`
+}
+
+// LineMatch contains the differences between two matched lines
+type LineMatch struct {
+	Lines            [2]int       `json:"Lines"`
+	Labels           [2]string    `json:"Labels"`
+	Score            float64      `json:"Score"` // Mean similarity over shared OPs [0-1]
+	MeanFreqDeltaHz  float32      `json:"MeanFreqDeltaHz"`
+	MaxFreqDeltaHz   float32      `json:"MaxFreqDeltaHz"` // Largest magnitude, with sign
+	MeanDampingDelta float32      `json:"MeanDampingDelta"`
+	Deltas           []PointDelta `json:"Deltas"`
+}
+
+// PointDelta contains the differences between matched lines at one
+// operating point. Deltas are the second diagram minus the first.
+type PointDelta struct {
+	OP            [2]int     `json:"OP"`
+	RotSpeed      float32    `json:"RotSpeed"`
+	WindSpeed     float32    `json:"WindSpeed"`
+	NaturalFreqHz [2]float32 `json:"NaturalFreqHz"`
+	DampingRatio  [2]float32 `json:"DampingRatio"`
+	MAC           float64    `json:"MAC"` // -1 if mode shapes couldn't be compared
+	FreqDeltaHz   float32    `json:"FreqDeltaHz"`
+	FreqDeltaPct  float32    `json:"FreqDeltaPct"`
+	DampingDelta  float32    `json:"DampingDelta"`
+}
+
+// Overlay contains the lines of both diagrams for plotting on one set of
+// axes. Matched lines in the second diagram use the color of the line they
+// were matched with and are dashed.
+type Overlay struct {
+	HasWind bool          `json:"HasWind"`
+	Lines   []OverlayLine `json:"Lines"`
+}
+
+type OverlayLine struct {
+	Diagram int  `json:"Diagram"` // 0 or 1
+	Match   int  `json:"Match"`   // Index in Comparison.Matches, -1 if unmatched
+	Line    Line `json:"Line"`
+}
+
+// Compare matches the lines of diagram d1 with the lines of diagram d2.
+// Lines are compared at operating points with the same rotor and wind speed
+// using the MAC of the mode shapes, penalized by the difference in frequency.
+// If OPs1 and OPs2 are nil or their states differ (e.g. ElastoDyn vs BeamDyn
+// blades), the lines are matched by frequency only.
+func Compare(d1, d2 *Diagram, OPs1, OPs2 []lin.LinOP, opts CompareOptions) (*Comparison, error) {
+
+	cmp := &Comparison{
+		OPs:       []OPPair{},
+		Matches:   []LineMatch{},
+		Unmatched: [2][]int{{}, {}},
+	}
+
+	//--------------------------------------------------------------------------
+	// Operating points
+	//--------------------------------------------------------------------------
+
+	// Cost of pairing operating points is the distance between their rotor
+	// and wind speeds, pairs outside the tolerance are forbidden
+	cost := NewFloatMatrix(len(d1.RotSpeeds), len(d2.RotSpeeds), math.Inf(1))
+	for i := range d1.RotSpeeds {
+		for j := range d2.RotSpeeds {
+			dRot := math.Abs(float64(d1.RotSpeeds[i] - d2.RotSpeeds[j]))
+			dWind := math.Abs(float64(d1.WindSpeeds[i] - d2.WindSpeeds[j]))
+			if dRot <= opts.OPTolerance && dWind <= opts.OPTolerance {
+				cost[i][j] = math.Hypot(dRot, dWind)
+			}
+		}
+	}
+
+	// Pair operating points one-to-one by nearest speeds
+	opPairs, err := LinearAssignment(cost)
+	if err != nil {
+		return nil, fmt.Errorf("error pairing operating points: %w", err)
+	}
+	for _, pair := range opPairs {
+		i, j := pair[0], pair[1]
+		cmp.OPs = append(cmp.OPs, OPPair{
+			OP:        [2]int{i, j},
+			RotSpeed:  [2]float32{d1.RotSpeeds[i], d2.RotSpeeds[j]},
+			WindSpeed: [2]float32{d1.WindSpeeds[i], d2.WindSpeeds[j]},
+		})
+	}
+
+	// Determine if mode shapes can be compared at each operating point pair
+	useMAC := make([]bool, len(cmp.OPs))
+	for k, opp := range cmp.OPs {
+		if opp.OP[0] < len(OPs1) && opp.OP[1] < len(OPs2) {
+			mbc1, mbc2 := OPs1[opp.OP[0]].MBC, OPs2[opp.OP[1]].MBC
+			useMAC[k] = mbc1 != nil && mbc2 != nil && slices.Equal(mbc1.DOFsEigen, mbc2.DOFsEigen)
+			cmp.UsedMAC = cmp.UsedMAC || useMAC[k]
+		}
+	}
+
+	//--------------------------------------------------------------------------
+	// Line similarity
+	//--------------------------------------------------------------------------
+
+	// Get frequency span of both diagrams to normalize frequency differences
+	fMin, fMax := math.Inf(1), math.Inf(-1)
+	for _, d := range []*Diagram{d1, d2} {
+		for _, line := range d.Lines {
+			for _, p := range line.Points {
+				fMin = min(fMin, float64(p.NaturalFreqHz))
+				fMax = max(fMax, float64(p.NaturalFreqHz))
+			}
+		}
+	}
+	fSpan := fMax - fMin
+	if !(fSpan > 0) {
+		fSpan = 1
+	}
+
+	// Map operating point to point for each line
+	linePoints := func(d *Diagram) []map[int]Point {
+		lps := make([]map[int]Point, len(d.Lines))
+		for i, line := range d.Lines {
+			lps[i] = map[int]Point{}
+			for _, p := range line.Points {
+				lps[i][p.OP] = p
+			}
+		}
+		return lps
+	}
+	points1, points2 := linePoints(d1), linePoints(d2)
+
+	// Calculate point deltas and similarity for every pair of lines
+	deltas := make([][][]PointDelta, len(d1.Lines))
+	scores := make([][]float64, len(d1.Lines))
+	for i := range d1.Lines {
+		deltas[i] = make([][]PointDelta, len(d2.Lines))
+		scores[i] = make([]float64, len(d2.Lines))
+		for j := range d2.Lines {
+
+			// Loop through shared operating points
+			sum := 0.0
+			for k, opp := range cmp.OPs {
+
+				// Skip operating points where either line has no point
+				p1, ok1 := points1[i][opp.OP[0]]
+				p2, ok2 := points2[j][opp.OP[1]]
+				if !ok1 || !ok2 {
+					continue
+				}
+
+				// Calculate MAC between modes if possible
+				mac := -1.0
+				if useMAC[k] {
+					m1 := findMode(OPs1[opp.OP[0]].Modes, p1.Mode)
+					m2 := findMode(OPs2[opp.OP[1]].Modes, p2.Mode)
+					if m1 != nil && m2 != nil {
+						if v, err := m1.MAC(m2); err == nil {
+							mac = v
+						}
+					}
+				}
+
+				// Similarity is MAC reduced by the change in frequency
+				sim := 1 - math.Abs(float64(p2.NaturalFreqHz-p1.NaturalFreqHz))/fSpan
+				if mac >= 0 {
+					sim *= mac
+				}
+				sum += max(sim, 0)
+
+				// Add point delta
+				pd := PointDelta{
+					OP:            opp.OP,
+					RotSpeed:      p1.RotSpeed,
+					WindSpeed:     p1.WindSpeed,
+					NaturalFreqHz: [2]float32{p1.NaturalFreqHz, p2.NaturalFreqHz},
+					DampingRatio:  [2]float32{p1.DampingRatio, p2.DampingRatio},
+					MAC:           mac,
+					FreqDeltaHz:   p2.NaturalFreqHz - p1.NaturalFreqHz,
+					DampingDelta:  p2.DampingRatio - p1.DampingRatio,
+				}
+				if p1.NaturalFreqHz != 0 {
+					pd.FreqDeltaPct = 100 * pd.FreqDeltaHz / p1.NaturalFreqHz
+				}
+				deltas[i][j] = append(deltas[i][j], pd)
+			}
+
+			// Line score is mean similarity over shared operating points
+			if n := len(deltas[i][j]); n > 0 {
+				scores[i][j] = sum / float64(n)
+			}
+		}
+	}
+
+	//--------------------------------------------------------------------------
+	// Line matching
+	//--------------------------------------------------------------------------
+
+	matched := [2][]bool{make([]bool, len(d1.Lines)), make([]bool, len(d2.Lines))}
+	if len(d1.Lines) > 0 && len(d2.Lines) > 0 {
+
+		// Find line pairings that maximize the total score
+		pairs, err := matchScores(scores)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add matches with sufficient score, pairs are ordered by line in d1
+		for _, pair := range pairs {
+			i, j := pair[0], pair[1]
+			if scores[i][j] <= opts.MinScore {
+				continue
+			}
+			lm := LineMatch{
+				Lines:  [2]int{d1.Lines[i].ID, d2.Lines[j].ID},
+				Labels: [2]string{d1.Lines[i].Label, d2.Lines[j].Label},
+				Score:  scores[i][j],
+				Deltas: deltas[i][j],
+			}
+			for _, pd := range lm.Deltas {
+				lm.MeanFreqDeltaHz += pd.FreqDeltaHz / float32(len(lm.Deltas))
+				lm.MeanDampingDelta += pd.DampingDelta / float32(len(lm.Deltas))
+				if math.Abs(float64(pd.FreqDeltaHz)) > math.Abs(float64(lm.MaxFreqDeltaHz)) {
+					lm.MaxFreqDeltaHz = pd.FreqDeltaHz
+				}
+			}
+			cmp.Matches = append(cmp.Matches, lm)
+			matched[0][i], matched[1][j] = true, true
+		}
+	}
+
+	// Collect unmatched lines
+	for n, d := range []*Diagram{d1, d2} {
+		for i, line := range d.Lines {
+			if !matched[n][i] {
+				cmp.Unmatched[n] = append(cmp.Unmatched[n], line.ID)
+			}
+		}
+	}
+
+	//--------------------------------------------------------------------------
+	// Overlay
+	//--------------------------------------------------------------------------
+
+	cmp.Overlay = Overlay{
+		HasWind: d1.HasWind || d2.HasWind,
+		Lines:   []OverlayLine{},
+	}
+	for n, d := range []*Diagram{d1, d2} {
+		for _, line := range d.Lines {
+			ol := OverlayLine{Diagram: n, Match: -1, Line: line}
+			for k, lm := range cmp.Matches {
+				if lm.Lines[n] == line.ID {
+					ol.Match = k
+				}
+			}
+			if n == 1 && ol.Match >= 0 {
+				for _, l1 := range d1.Lines {
+					if l1.ID == cmp.Matches[ol.Match].Lines[0] {
+						ol.Line.Color = l1.Color
+					}
+				}
+				ol.Line.Dash = []int{6, 4}
+			}
+			cmp.Overlay.Lines = append(cmp.Overlay.Lines, ol)
+		}
+	}
+
+	return cmp, nil
+}
+
+// findMode returns the mode with the given ID or nil if not found
+func findMode(modes lin.Modes, id int) *lin.Mode {
+	if id >= 0 && id < len(modes) && modes[id].ID == id {
+		return &modes[id]
+	}
+	for i := range modes {
+		if modes[i].ID == id {
+			return &modes[i]
+		}
+	}
+	return nil
+}
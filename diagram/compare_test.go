@@ -0,0 +1,118 @@
+package diagram_test
+
+import (
+	"acdc/diagram"
+	"testing"
+)
+
+// newTestDiagram creates a diagram with a line for each frequency slice
+func newTestDiagram(rotSpeeds []float32, lineFreqs [][]float32) *diagram.Diagram {
+	d := &diagram.Diagram{
+		RotSpeeds:  rotSpeeds,
+		WindSpeeds: make([]float32, len(rotSpeeds)),
+	}
+	for i, freqs := range lineFreqs {
+		line := diagram.Line{ID: i, Label: string(rune('A' + i)), Color: "red"}
+		for op, f := range freqs {
+			line.Points = append(line.Points, diagram.Point{
+				Line:          i,
+				OP:            op,
+				Mode:          i,
+				RotSpeed:      rotSpeeds[op],
+				NaturalFreqHz: f,
+				DampingRatio:  0.01 * float32(i+1),
+			})
+		}
+		d.Lines = append(d.Lines, line)
+	}
+	return d
+}
+
+func TestCompare(t *testing.T) {
+
+	d1 := newTestDiagram([]float32{5, 10}, [][]float32{
+		{0.3, 0.32},
+		{0.6, 0.7},
+	})
+
+	// Second diagram has lines in different order, an extra OP, and an extra line
+	d2 := newTestDiagram([]float32{5, 10, 12}, [][]float32{
+		{0.65, 0.75, 0.8},
+		{2.0, 2.1, 2.2},
+		{0.31, 0.33, 0.35},
+	})
+
+	cmp, err := diagram.Compare(d1, d2, nil, nil, diagram.CompareOptions{OPTolerance: 0.1, MinScore: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cmp.UsedMAC {
+		t.Fatal("MAC should not be used without linearization data")
+	}
+	if act, exp := len(cmp.OPs), 2; act != exp {
+		t.Fatalf("len(OPs) = %v, expected %v", act, exp)
+	}
+
+	// Lines should be matched by frequency
+	if act, exp := len(cmp.Matches), 2; act != exp {
+		t.Fatalf("len(Matches) = %v, expected %v", act, exp)
+	}
+	for i, exp := range [][2]int{{0, 2}, {1, 0}} {
+		if act := cmp.Matches[i].Lines; act != exp {
+			t.Fatalf("Matches[%d].Lines = %v, expected %v", i, act, exp)
+		}
+	}
+
+	// Deltas are only calculated at shared operating points
+	lm := cmp.Matches[1]
+	if act, exp := len(lm.Deltas), 2; act != exp {
+		t.Fatalf("len(Deltas) = %v, expected %v", act, exp)
+	}
+	if act, exp := lm.Deltas[1].FreqDeltaHz, float32(0.05); act < exp-1e-6 || act > exp+1e-6 {
+		t.Fatalf("FreqDeltaHz = %v, expected %v", act, exp)
+	}
+	if act, exp := lm.Deltas[0].DampingDelta, float32(-0.01); act < exp-1e-6 || act > exp+1e-6 {
+		t.Fatalf("DampingDelta = %v, expected %v", act, exp)
+	}
+
+	// Line far from all others should be unmatched
+	if act := cmp.Unmatched[1]; len(act) != 1 || act[0] != 1 {
+		t.Fatalf("Unmatched[1] = %v, expected [1]", act)
+	}
+	if act := cmp.Unmatched[0]; len(act) != 0 {
+		t.Fatalf("Unmatched[0] = %v, expected []", act)
+	}
+
+	// Overlay should contain all lines, matched lines from second diagram dashed
+	if act, exp := len(cmp.Overlay.Lines), 5; act != exp {
+		t.Fatalf("len(Overlay.Lines) = %v, expected %v", act, exp)
+	}
+	for _, ol := range cmp.Overlay.Lines {
+		if dashed := len(ol.Line.Dash) > 0; dashed != (ol.Diagram == 1 && ol.Match >= 0) {
+			t.Fatalf("overlay line %d of diagram %d dashed = %v", ol.Line.ID, ol.Diagram, dashed)
+		}
+	}
+}
+
+func TestCompareOPPairing(t *testing.T) {
+
+	// Both OPs of the first diagram are within tolerance of the second
+	// diagram's first and second OPs, each should be paired with the nearest
+	d1 := newTestDiagram([]float32{5, 5.08, 10}, [][]float32{{0.3, 0.3, 0.32}})
+	d2 := newTestDiagram([]float32{5.06, 5.01, 9.96, 10.02}, [][]float32{{0.3, 0.3, 0.32, 0.32}})
+
+	cmp, err := diagram.Compare(d1, d2, nil, nil, diagram.CompareOptions{OPTolerance: 0.1, MinScore: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if act, exp := len(cmp.OPs), 3; act != exp {
+		t.Fatalf("len(OPs) = %v, expected %v", act, exp)
+	}
+	for i, exp := range [][2]int{{0, 1}, {1, 0}, {2, 3}} {
+		if act := cmp.OPs[i].OP; act != exp {
+			t.Fatalf("OPs[%d].OP = %v, expected %v", i, act, exp)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package diagram
+
+import (
+	"acdc/lin"
+)
+
+// linkScore describes how a mode was attached to the previous mode in its
+// line and the best alternative mode at the same operating point
+type linkScore struct {
+	Correlation    float64
+	Weight         float64
+	RunnerUpMode   int
+	RunnerUpWeight float64
+}
+
+// LineSwap identifies a point where the line may have been swapped with
+// another line because each line's runner-up mode is in the other line.
+type LineSwap struct {
+	OP   int `json:"OP"`
+	Line int `json:"Line"`
+}
+
+// scoreLinks calculates the link score of every mode in the mode sets by
+// comparing it to the previous mode in its set with the tracking weight.
+// The first mode in each set has a weight of one and no runner-up.
+func scoreLinks(OPs []lin.LinOP, modeSets []*ModeSet, opts Options) (map[*lin.Mode]linkScore, error) {
+
+	freqRangeHz := [2]float64{opts.MinFreq, opts.MaxFreq}
+
+	scores := map[*lin.Mode]linkScore{}
+	for _, ms := range modeSets {
+		for i, m := range ms.Modes {
+
+			// First mode in set isn't linked
+			if i == 0 {
+				scores[m] = linkScore{Correlation: 1, Weight: 1, RunnerUpMode: -1}
+				continue
+			}
+
+			// Calculate weight between previous mode and candidate
+			mp := ms.Modes[i-1]
+			weight := func(mn *lin.Mode) (float64, float64, error) {
+				c, err := correlation(opts.Metric, mp, mn)
+				if err != nil {
+					return 0, 0, err
+				}
+				w := c * trackingPenalty(mp, mn, freqRangeHz, opts)
+				if gap := mn.OP - mp.OP - 1; gap > 0 {
+					w -= gapPenalty * float64(gap)
+				}
+				return c, w, nil
+			}
+
+			// Get score of link to this mode
+			c, w, err := weight(m)
+			if err != nil {
+				return nil, err
+			}
+			score := linkScore{Correlation: c, Weight: w, RunnerUpMode: -1}
+
+			// Find best alternative mode at the same operating point
+			for j := range OPs[m.OP].Modes {
+				mn := &OPs[m.OP].Modes[j]
+				if mn.ID == m.ID || !mn.Filter(freqRangeHz, opts.FilterStruct) {
+					continue
+				}
+				_, w, err := weight(mn)
+				if err != nil {
+					return nil, err
+				}
+				if score.RunnerUpMode < 0 || w > score.RunnerUpWeight {
+					score.RunnerUpMode = mn.ID
+					score.RunnerUpWeight = w
+				}
+			}
+
+			scores[m] = score
+		}
+	}
+
+	return scores, nil
+}
+
+// flagLinks sets the low confidence and swap flags of the lines. A link is
+// low confidence if its weight exceeds the runner-up weight by less than the
+// margin. A swap is flagged if two lines have low confidence links at the
+// same operating point and each line's runner-up mode is in the other line.
+func flagLinks(lines []Line, margin float64) {
+
+	// Map operating point and mode to line index and point
+	type opMode struct{ OP, Mode int }
+	pointLines := map[opMode]int{}
+	points := map[opMode]Point{}
+	for i, line := range lines {
+		for _, p := range line.Points {
+			pointLines[opMode{p.OP, p.Mode}] = i
+			points[opMode{p.OP, p.Mode}] = p
+		}
+	}
+
+	// Returns true if the point's link is low confidence
+	isLow := func(p Point) bool {
+		return p.RunnerUpMode >= 0 && float64(p.Weight-p.RunnerUpWeight) < margin
+	}
+
+	for i := range lines {
+		line := &lines[i]
+		line.LowConfidence = []int{}
+		line.Swaps = []LineSwap{}
+		for _, p := range line.Points {
+
+			if !isLow(p) {
+				continue
+			}
+			line.LowConfidence = append(line.LowConfidence, p.OP)
+
+			// Check if runner-up mode is in another line whose runner-up is this mode
+			j, ok := pointLines[opMode{p.OP, p.RunnerUpMode}]
+			if !ok || j == i {
+				continue
+			}
+			q := points[opMode{p.OP, p.RunnerUpMode}]
+			if isLow(q) && q.RunnerUpMode == p.Mode {
+				line.Swaps = append(line.Swaps, LineSwap{OP: p.OP, Line: lines[j].ID})
+			}
+		}
+	}
+}
@@ -3,14 +3,19 @@ package diagram
 import (
 	"acdc/lin"
 	"fmt"
+	"maps"
 	"math"
+	"slices"
 	"sort"
 
 	"gonum.org/v1/gonum/mat"
 )
 
 // connectModesMAC builds connected sets of modes from linearization results
-func connectModesMAC(OPs []lin.LinOP, freqRangeHz [2]float64, structMax bool) ([]*ModeSet, error) {
+func connectModesMAC(OPs []lin.LinOP, opts Options) ([]*ModeSet, error) {
+
+	freqRangeHz := [2]float64{opts.MinFreq, opts.MaxFreq}
+	structMax := opts.FilterStruct
 
 	// Create array of mode sets
 	modeSets := []*ModeSet{}
@@ -42,8 +47,9 @@ func connectModesMAC(OPs []lin.LinOP, freqRangeHz [2]float64, structMax bool) ([
 			continue
 		}
 
-		// Create empty weighting matrix
+		// Create empty weighting and correlation matrices
 		w := mat.NewDense(len(modeSets), len(op.Modes), nil)
+		corr := mat.NewDense(len(modeSets), len(op.Modes), nil)
 
 		// Create map mapping mode index to mode
 		modeIndexMap := map[int]*lin.Mode{}
@@ -66,17 +72,15 @@ func connectModesMAC(OPs []lin.LinOP, freqRangeHz [2]float64, structMax bool) ([
 					continue
 				}
 
-				// Calculate MAC between modes
-				mac, err := mp.MAC(mn)
+				// Calculate correlation between mode shapes
+				c, err := correlation(opts.Metric, mp, mn)
 				if err != nil {
 					return nil, err
 				}
+				corr.Set(j, k, c)
 
-				// Modify MAC by change in frequency
-				mac *= 1 - math.Abs(mn.NaturalFreqHz-mp.NaturalFreqHz)/(freqRangeHz[1]-freqRangeHz[0])
-
-				// Add MAC to weight matrix
-				w.Set(j, k, mac)
+				// Add correlation modified by change in frequency and damping to weight matrix
+				w.Set(j, k, c*trackingPenalty(mp, mn, freqRangeHz, opts))
 
 				// Add mode to index map
 				modeIndexMap[k] = mn
@@ -85,20 +89,21 @@ func connectModesMAC(OPs []lin.LinOP, freqRangeHz [2]float64, structMax bool) ([
 			}
 		}
 
-		// Get max weight value
-		wMax := mat.Max(w)
-
-		// Create cost matrix (ints) from weights (rescale to maximize precision)
-		cost := NewIntMatrix(len(modeSets), len(modeIndexMap), 0)
+		// Create cost matrix from negative weights so the total weight is
+		// maximized, forbid pairs with correlation below the minimum
+		cost := NewFloatMatrix(len(modeSets), len(modeIndexMap), 0)
 		for j := range cost {
 			for k := range cost[j] {
-				v := w.At(j, k)
-				cost[j][k] = int(1e7 * (1 - v/wMax))
+				if corr.At(j, k) < opts.MinCorrelation {
+					cost[j][k] = math.Inf(1)
+				} else {
+					cost[j][k] = -w.At(j, k)
+				}
 			}
 		}
 
-		// Find mode pairings that minimizes the total cost
-		pairs, err := MinCostAssignment(cost)
+		// Find mode pairings that maximize the total weight
+		pairs, err := LinearAssignment(cost)
 		if err != nil {
 			return nil, err
 		}
@@ -116,8 +121,9 @@ func connectModesMAC(OPs []lin.LinOP, freqRangeHz [2]float64, structMax bool) ([
 			delete(modeIndexMap, pair[1])
 		}
 
-		// Loop through unpaired modes and create new mode sets
-		for _, m := range modeIndexMap {
+		// Loop through unpaired modes in index order and create new mode sets
+		for _, k := range slices.Sorted(maps.Keys(modeIndexMap)) {
+			m := modeIndexMap[k]
 			modeSets = append(modeSets, &ModeSet{
 				ID:    len(modeSets),
 				Label: fmt.Sprintf("%d", len(modeSets)),
@@ -126,6 +132,13 @@ func connectModesMAC(OPs []lin.LinOP, freqRangeHz [2]float64, structMax bool) ([
 		}
 	}
 
+	return finalizeModeSets(modeSets), nil
+}
+
+// finalizeModeSets removes empty mode sets, calculates the frequency range
+// of each set, and sorts the sets by minimum frequency.
+func finalizeModeSets(modeSets []*ModeSet) []*ModeSet {
+
 	// Create temporary slice for filtering mode sets
 	allModeSets := modeSets
 	modeSets = modeSets[:0]
@@ -153,9 +166,31 @@ func connectModesMAC(OPs []lin.LinOP, freqRangeHz [2]float64, structMax bool) ([
 	}
 
 	// Sort mode sets by minimum frequency
-	sort.Slice(modeSets, func(i, j int) bool {
+	sort.SliceStable(modeSets, func(i, j int) bool {
 		return modeSets[i].Frequency[0] < modeSets[j].Frequency[0]
 	})
 
-	return modeSets, nil
+	return modeSets
+}
+
+// correlation returns the similarity of the mode shapes using the metric
+func correlation(metric string, m1, m2 *lin.Mode) (float64, error) {
+	switch metric {
+	case MetricMAC, "":
+		return m1.MAC(m2)
+	case MetricMACX:
+		return m1.MACX(m2)
+	case MetricMACXP:
+		return m1.MACXP(m2)
+	}
+	return 0, fmt.Errorf("unknown tracking metric '%s'", metric)
+}
+
+// trackingPenalty returns a factor in [0,1] which reduces the weight of a
+// mode pairing by the weighted change in natural frequency, relative to the
+// frequency range, and the weighted change in damping ratio.
+func trackingPenalty(m1, m2 *lin.Mode, freqRangeHz [2]float64, opts Options) float64 {
+	freqPenalty := 1 - opts.FreqWeight*math.Abs(m2.NaturalFreqHz-m1.NaturalFreqHz)/(freqRangeHz[1]-freqRangeHz[0])
+	dampPenalty := 1 - opts.DampWeight*math.Abs(m2.DampingRatio-m1.DampingRatio)
+	return max(freqPenalty, 0) * max(dampPenalty, 0)
 }
@@ -2,24 +2,77 @@ package diagram
 
 import (
 	"acdc/lin"
-	"encoding/json"
-	"os"
-	"strconv"
+	"fmt"
 )
 
 // Diagram contains data for drawing the Campbell Diagram
 type Diagram struct {
-	HasWind    bool      `json:"HasWind"`
-	RotSpeeds  []float32 `json:"RotSpeeds"`
-	WindSpeeds []float32 `json:"WindSpeeds"`
-	Lines      []Line    `json:"Lines"`
+	SchemaVersion int       `json:"SchemaVersion"`
+	HasWind       bool      `json:"HasWind"`
+	RotSpeeds     []float32 `json:"RotSpeeds"`
+	WindSpeeds    []float32 `json:"WindSpeeds"`
+	Lines         []Line    `json:"Lines"`
+	Options       Options   `json:"Options"` // Options used to generate the diagram
 }
 
 type Options struct {
-	MinFreq      float64 `json:"MinFreq"`
-	MaxFreq      float64 `json:"MaxFreq"`
-	Cluster      bool    `json:"Cluster"`
-	FilterStruct bool    `json:"FilterStruct"`
+	MinFreq          float64 `json:"MinFreq"`
+	MaxFreq          float64 `json:"MaxFreq"`
+	Cluster          bool    `json:"Cluster"`
+	FilterStruct     bool    `json:"FilterStruct"`
+	Metric           string  `json:"Metric"`           // Mode shape correlation metric: MAC, MACX, or MACXP
+	FreqWeight       float64 `json:"FreqWeight"`       // Weight of natural frequency difference penalty
+	DampWeight       float64 `json:"DampWeight"`       // Weight of damping ratio difference penalty
+	MinCorrelation   float64 `json:"MinCorrelation"`   // Modes with lower correlation start a new line
+	Tracking         string  `json:"Tracking"`         // Mode tracking algorithm: Sequential or Global
+	LookAhead        int     `json:"LookAhead"`        // Max operating points a line can skip (Global)
+	ConfidenceMargin float64 `json:"ConfidenceMargin"` // Min weight above runner-up for a confident link
+	Seed             int64   `json:"Seed"`             // Random seed for spectral clustering
+}
+
+// validate returns an error if the options can't be used to weight mode
+// pairings: the frequency range must be positive as frequency differences
+// are relative to it, and penalty weights can't be negative.
+func (opts Options) validate() error {
+	if !(opts.MaxFreq > opts.MinFreq) {
+		return fmt.Errorf("max frequency (%g) must be greater than min frequency (%g)", opts.MaxFreq, opts.MinFreq)
+	}
+	if opts.FreqWeight < 0 {
+		return fmt.Errorf("frequency weight (%g) can't be negative", opts.FreqWeight)
+	}
+	if opts.DampWeight < 0 {
+		return fmt.Errorf("damping weight (%g) can't be negative", opts.DampWeight)
+	}
+	return nil
+}
+
+// Mode shape correlation metrics used for tracking modes between operating points
+const (
+	MetricMAC   = "MAC"
+	MetricMACX  = "MACX"
+	MetricMACXP = "MACXP"
+)
+
+// Mode tracking algorithms. Sequential pairs the modes at each operating
+// point with the last mode of each line and may be refined by clustering.
+// Global links modes across all operating points at once and ignores the
+// clustering option.
+const (
+	TrackingSequential = "Sequential"
+	TrackingGlobal     = "Global"
+)
+
+// NewOptions returns diagram options with the default tracking settings
+func NewOptions() Options {
+	return Options{
+		MaxFreq:          10,
+		Metric:           MetricMAC,
+		FreqWeight:       1,
+		Tracking:         TrackingSequential,
+		LookAhead:        2,
+		ConfidenceMargin: 0.1,
+		Seed:             1,
+	}
 }
 
 type Line struct {
@@ -29,6 +82,9 @@ type Line struct {
 	Dash   []int   `json:"Dash"`
 	Hidden bool    `json:"Hidden"`
 	Points []Point `json:"Points"`
+
+	LowConfidence []int      `json:"LowConfidence"` // OPs where the point's link is low confidence
+	Swaps         []LineSwap `json:"Swaps"`         // Points where the line may be swapped with another
 }
 
 type Point struct {
@@ -40,6 +96,12 @@ type Point struct {
 	NaturalFreqHz float32 `json:"NaturalFreqHz"`
 	DampedFreqHz  float32 `json:"DampedFreqHz"`
 	DampingRatio  float32 `json:"DampingRatio"`
+
+	// Link to previous point in line, the first point has a weight of one
+	Correlation    float32 `json:"Correlation"`    // Mode shape correlation
+	Weight         float32 `json:"Weight"`         // Tracking weight including penalties
+	RunnerUpMode   int     `json:"RunnerUpMode"`   // Next best mode at this OP, -1 if none
+	RunnerUpWeight float32 `json:"RunnerUpWeight"` // Tracking weight of runner-up mode
 }
 
 type ModeSet struct {
@@ -58,6 +120,11 @@ type ModeIndex struct {
 // CampbellDiagram returns a Campbell Diagram structure from the results
 func New(OPs []lin.LinOP, opts Options) (*Diagram, error) {
 
+	// Check options used to weight mode pairings
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
 	// Collect operating point data
 	rotSpeeds := make([]float32, len(OPs))
 	windSpeeds := make([]float32, len(OPs))
@@ -68,17 +135,36 @@ func New(OPs []lin.LinOP, opts Options) (*Diagram, error) {
 		hasWind = linOP.MBC.WindSpeed > 0 || hasWind
 	}
 
-	// Build mode sets based on modal assurance criteria
-	modeSets, err := connectModesMAC(OPs, [2]float64{opts.MinFreq, opts.MaxFreq}, opts.FilterStruct)
-	if err != nil {
-		return nil, err
-	}
+	// Build mode sets using the selected tracking algorithm
+	var modeSets []*ModeSet
+	var err error
+	switch opts.Tracking {
+
+	case TrackingGlobal:
+
+		// Link modes across all operating points
+		modeSets, err = trackModesGlobal(OPs, opts)
+		if err != nil {
+			return nil, err
+		}
+
+	case TrackingSequential, "":
 
-	// Refine mode sets using spectral clustering
-	if opts.Cluster {
-		if err := clusterModes(OPs, modeSets); err != nil {
+		// Build mode sets based on modal assurance criteria
+		modeSets, err = connectModesMAC(OPs, opts)
+		if err != nil {
 			return nil, err
 		}
+
+		// Refine mode sets using spectral clustering
+		if opts.Cluster {
+			if err := clusterModes(OPs, modeSets, opts.Seed); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown tracking algorithm '%s'", opts.Tracking)
 	}
 
 	// Create diagram lines
@@ -86,7 +172,7 @@ func New(OPs []lin.LinOP, opts Options) (*Diagram, error) {
 	for i, ms := range modeSets {
 		line := Line{
 			ID:     i,
-			Label:  "Line " + strconv.Itoa(i+1),
+			Label:  defaultLabel(i),
 			Points: make([]Point, len(ms.Modes)),
 		}
 		for j, m := range ms.Modes {
@@ -104,26 +190,19 @@ func New(OPs []lin.LinOP, opts Options) (*Diagram, error) {
 		lines = append(lines, line)
 	}
 
-	// Return the diagram
-	return &Diagram{
+	// Create the diagram
+	diag := &Diagram{
 		HasWind:    hasWind,
 		RotSpeeds:  rotSpeeds,
 		WindSpeeds: windSpeeds,
 		Lines:      lines,
-	}, nil
-}
-
-func Load(path string) (*Diagram, error) {
-
-	d := Diagram{}
-	bs, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+		Options:    opts,
 	}
 
-	if err := json.Unmarshal(bs, &d); err != nil {
+	// Score links between points and flag low confidence links
+	if err := diag.Relink(OPs); err != nil {
 		return nil, err
 	}
 
-	return &d, nil
+	return diag, nil
 }
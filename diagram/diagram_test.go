@@ -3,8 +3,12 @@ package diagram_test
 import (
 	"acdc/diagram"
 	"acdc/lin"
+	"context"
+	"math"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
 	"testing"
 )
 
@@ -24,8 +28,11 @@ func TestDiagramNew(t *testing.T) {
 		}
 	}
 
-	// Process linearization files into results
-	linOPs, err := lin.ProcessFiles(LinFiles)
+	// Process linearization files into results without cache so cache
+	// files aren't written to testdata
+	opts := lin.NewProcessOptions()
+	opts.UseCache = false
+	linOPs, err := lin.ProcessFiles(context.Background(), LinFiles, opts)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -41,3 +48,227 @@ func TestDiagramNew(t *testing.T) {
 
 	t.Logf("%#v", diag)
 }
+
+// newTestOP creates an operating point with modes having the given natural
+// frequencies and two-state eigenvectors.
+func newTestOP(opID int, rotSpeed float64, freqs []float64, shapes [][]complex128) lin.LinOP {
+	op := lin.LinOP{MBC: &lin.MBC{RotSpeed: rotSpeed}}
+	for i, f := range freqs {
+		op.Modes = append(op.Modes, lin.Mode{
+			ID:            i,
+			OP:            opID,
+			NaturalFreqHz: f,
+			DampedFreqHz:  f,
+			EigenValue:    complex(-0.1, 2*math.Pi*f),
+			EigenVector:   shapes[i],
+			EigenIndices:  []int{0, 1},
+		})
+	}
+	return op
+}
+
+func TestDiagramTrackingOptions(t *testing.T) {
+
+	// Mode shapes swap between operating points while frequencies stay close
+	OPs := []lin.LinOP{
+		newTestOP(0, 5, []float64{1.0, 1.1}, [][]complex128{{1, 0}, {0, 1}}),
+		newTestOP(1, 10, []float64{1.0, 1.1}, [][]complex128{{0, 1}, {1, 0.05}}),
+	}
+
+	for _, metric := range []string{diagram.MetricMAC, diagram.MetricMACX, diagram.MetricMACXP} {
+
+		// Without frequency penalty, modes should be tracked by shape
+		opts := diagram.NewOptions()
+		opts.Metric = metric
+		opts.FreqWeight = 0
+		diag, err := diagram.New(OPs, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if act, exp := len(diag.Lines), 2; act != exp {
+			t.Fatalf("%s: len(Lines) = %v, expected %v", metric, act, exp)
+		}
+		for _, line := range diag.Lines {
+			if line.Points[0].Mode == line.Points[1].Mode {
+				t.Fatalf("%s: line %d connects modes with different shapes", metric, line.ID)
+			}
+		}
+	}
+
+	// Modes with low correlation should start new lines
+	OPs[1] = newTestOP(1, 10, []float64{1.0, 1.1}, [][]complex128{{1, 1}, {1, -1}})
+	opts := diagram.NewOptions()
+	opts.MinCorrelation = 0.8
+	diag, err := diagram.New(OPs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(diag.Lines), 4; act != exp {
+		t.Fatalf("len(Lines) = %v, expected %v", act, exp)
+	}
+
+	// Unknown metric should return an error
+	opts.Metric = "XYZ"
+	if _, err := diagram.New(OPs, opts); err == nil {
+		t.Fatal("expected error for unknown metric")
+	}
+
+	// Options that can't weight mode pairings should return an error
+	for _, modify := range []func(*diagram.Options){
+		func(o *diagram.Options) { o.MaxFreq = o.MinFreq },
+		func(o *diagram.Options) { o.FreqWeight = -1 },
+		func(o *diagram.Options) { o.DampWeight = -1 },
+	} {
+		opts := diagram.NewOptions()
+		modify(&opts)
+		if _, err := diagram.New(OPs, opts); err == nil {
+			t.Fatalf("expected error for options %+v", opts)
+		}
+	}
+}
+
+func TestDiagramGlobalTracking(t *testing.T) {
+
+	// Mode 0 is missing at the second operating point and an unrelated mode
+	// with a close frequency appears instead
+	OPs := []lin.LinOP{
+		newTestOP(0, 5, []float64{1.0, 1.5}, [][]complex128{{1, 0, 0}, {0, 1, 0}}),
+		newTestOP(1, 10, []float64{1.05, 1.5}, [][]complex128{{0, 0.6, 0.8}, {0, 1, 0}}),
+		newTestOP(2, 15, []float64{1.0, 1.5}, [][]complex128{{1, 0, 0}, {0, 1, 0}}),
+	}
+	for i := range OPs {
+		for j := range OPs[i].Modes {
+			OPs[i].Modes[j].EigenIndices = []int{0, 1, 2}
+		}
+	}
+
+	// Line starting at first mode, as (OP, mode) pairs
+	firstLine := func(diag *diagram.Diagram) [][2]int {
+		for _, line := range diag.Lines {
+			if p := line.Points[0]; p.OP == 0 && p.Mode == 0 {
+				pts := [][2]int{}
+				for _, p := range line.Points {
+					pts = append(pts, [2]int{p.OP, p.Mode})
+				}
+				return pts
+			}
+		}
+		return nil
+	}
+
+	// Sequential tracking is forced to connect the unrelated mode
+	opts := diagram.NewOptions()
+	diag, err := diagram.New(OPs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(firstLine(diag)), 3; act != exp {
+		t.Fatalf("sequential: len(points) = %v, expected %v", act, exp)
+	}
+
+	// Global tracking should bridge the missing mode
+	opts.Tracking = diagram.TrackingGlobal
+	diag, err = diagram.New(OPs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := firstLine(diag), [][2]int{{0, 0}, {2, 0}}; !slices.Equal(act, exp) {
+		t.Fatalf("global: points = %v, expected %v", act, exp)
+	}
+	if act, exp := len(diag.Lines), 3; act != exp {
+		t.Fatalf("global: len(Lines) = %v, expected %v", act, exp)
+	}
+
+	// Without look ahead, the gap can't be bridged
+	opts.LookAhead = 0
+	diag, err = diagram.New(OPs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(diag.Lines), 4; act != exp {
+		t.Fatalf("no look ahead: len(Lines) = %v, expected %v", act, exp)
+	}
+}
+
+func TestDiagramConfidence(t *testing.T) {
+
+	// Second operating point has mode shapes equally similar to both modes
+	// at the first operating point, third is clearly connected to the second
+	OPs := []lin.LinOP{
+		newTestOP(0, 5, []float64{1.0, 1.1}, [][]complex128{{1, 0}, {0, 1}}),
+		newTestOP(1, 10, []float64{1.0, 1.1}, [][]complex128{{1, 1}, {1, -1}}),
+		newTestOP(2, 15, []float64{1.0, 1.1}, [][]complex128{{1, 1}, {1, -1}}),
+	}
+
+	diag, err := diagram.New(OPs, diagram.NewOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(diag.Lines), 2; act != exp {
+		t.Fatalf("len(Lines) = %v, expected %v", act, exp)
+	}
+
+	for _, line := range diag.Lines {
+
+		// First point isn't linked, second has runner-up with same correlation
+		p0, p1, p2 := line.Points[0], line.Points[1], line.Points[2]
+		if p0.Weight != 1 || p0.RunnerUpMode != -1 {
+			t.Fatalf("line %d first point = %+v", line.ID, p0)
+		}
+		if act, exp := p1.Correlation, float32(0.5); math.Abs(float64(act-exp)) > 1e-6 {
+			t.Fatalf("line %d Correlation = %v, expected %v", line.ID, act, exp)
+		}
+		if act, exp := p1.RunnerUpMode, 1-p1.Mode; act != exp {
+			t.Fatalf("line %d RunnerUpMode = %v, expected %v", line.ID, act, exp)
+		}
+		if p2.Weight-p2.RunnerUpWeight < 0.5 {
+			t.Fatalf("line %d third point should be confident: %+v", line.ID, p2)
+		}
+
+		// Only the ambiguous link is flagged, as a swap with the other line
+		if !slices.Equal(line.LowConfidence, []int{1}) {
+			t.Fatalf("line %d LowConfidence = %v, expected [1]", line.ID, line.LowConfidence)
+		}
+		if act, exp := line.Swaps, []diagram.LineSwap{{OP: 1, Line: 1 - line.ID}}; !slices.Equal(act, exp) {
+			t.Fatalf("line %d Swaps = %v, expected %v", line.ID, act, exp)
+		}
+	}
+}
+
+func TestDiagramClusterSeed(t *testing.T) {
+
+	// Closely spaced modes so all lines are clustered together
+	OPs := []lin.LinOP{}
+	for i := 0; i < 4; i++ {
+		s := 0.1 * float64(i)
+		OPs = append(OPs, newTestOP(i, 5*float64(i+1), []float64{1.0, 1.02, 1.04}, [][]complex128{
+			{1, complex(s, 0)},
+			{complex(s, 0), 1},
+			{1, complex(1-s, 0.2)},
+		}))
+	}
+
+	opts := diagram.NewOptions()
+	opts.Cluster = true
+	opts.Seed = 7
+
+	// Diagrams generated with the same seed should be identical
+	diag1, err := diagram.New(OPs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		diag2, err := diagram.New(OPs, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(diag1, diag2) {
+			t.Fatalf("diagrams generated with seed %d differ", opts.Seed)
+		}
+	}
+
+	// Seed should be recorded in diagram
+	if act, exp := diag1.Options.Seed, opts.Seed; act != exp {
+		t.Fatalf("Options.Seed = %v, expected %v", act, exp)
+	}
+}
@@ -0,0 +1,282 @@
+package diagram
+
+import (
+	"acdc/lin"
+	"fmt"
+	"slices"
+)
+
+// MergeLines moves the points of line 2 into line 1 and removes line 2.
+// The lines can't both have points at the same operating point.
+func (d *Diagram) MergeLines(lineID1, lineID2 int) error {
+
+	// Find lines
+	i1, err := d.lineIndex(lineID1)
+	if err != nil {
+		return err
+	}
+	i2, err := d.lineIndex(lineID2)
+	if err != nil {
+		return err
+	}
+	if i1 == i2 {
+		return fmt.Errorf("can't merge line %d with itself", lineID1)
+	}
+
+	// Check that merged line has at most one point per operating point
+	points := append(slices.Clone(d.Lines[i1].Points), d.Lines[i2].Points...)
+	if err := checkPointOPs(points); err != nil {
+		return fmt.Errorf("can't merge line %d into line %d: %w", lineID2, lineID1, err)
+	}
+
+	// Update points of line 1 and remove line 2
+	d.Lines[i1].Points = points
+	d.Lines[i1].normalize()
+	d.Lines = slices.Delete(d.Lines, i2, i2+1)
+
+	d.flagLinks()
+	return nil
+}
+
+// SplitLine moves the points of the line at and after the operating point
+// into a new line, which is inserted after the line. It returns the ID of
+// the new line.
+func (d *Diagram) SplitLine(lineID, op int) (int, error) {
+
+	// Find line
+	i, err := d.lineIndex(lineID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Split points at operating point
+	before, after := splitPoints(d.Lines[i].Points, op)
+	if len(before) == 0 || len(after) == 0 {
+		return 0, fmt.Errorf("line %d has no points on one side of operating point %d", lineID, op)
+	}
+
+	// Create new line with the next available ID
+	newLine := Line{ID: d.nextLineID(), Points: after}
+	newLine.Label = defaultLabel(newLine.ID)
+	newLine.normalize()
+
+	// Update line and insert new line after it
+	d.Lines[i].Points = before
+	d.Lines = slices.Insert(d.Lines, i+1, newLine)
+
+	d.flagLinks()
+	return newLine.ID, nil
+}
+
+// MovePoint moves the point with the operating point and mode to the line.
+// If the line the point was in has no remaining points, it's removed.
+func (d *Diagram) MovePoint(op, modeID, lineID int) error {
+
+	// Find destination line
+	iDst, err := d.lineIndex(lineID)
+	if err != nil {
+		return err
+	}
+
+	// Find line and index of point
+	iSrc, j := -1, -1
+	for i, line := range d.Lines {
+		if k := slices.IndexFunc(line.Points, func(p Point) bool {
+			return p.OP == op && p.Mode == modeID
+		}); k >= 0 {
+			iSrc, j = i, k
+			break
+		}
+	}
+	if iSrc < 0 {
+		return fmt.Errorf("point for mode %d at operating point %d not found", modeID, op)
+	}
+	if iSrc == iDst {
+		return nil
+	}
+
+	// Check that destination line doesn't have a point at the operating point
+	if slices.ContainsFunc(d.Lines[iDst].Points, func(p Point) bool { return p.OP == op }) {
+		return fmt.Errorf("line %d already has a point at operating point %d", lineID, op)
+	}
+
+	// Move point to destination line
+	d.Lines[iDst].Points = append(d.Lines[iDst].Points, d.Lines[iSrc].Points[j])
+	d.Lines[iDst].normalize()
+	d.Lines[iSrc].Points = slices.Delete(d.Lines[iSrc].Points, j, j+1)
+
+	// Remove source line if empty
+	if len(d.Lines[iSrc].Points) == 0 {
+		d.Lines = slices.Delete(d.Lines, iSrc, iSrc+1)
+	}
+
+	d.flagLinks()
+	return nil
+}
+
+// SwapLines exchanges the points of the two lines at and after the
+// operating point. Lines left without points are removed.
+func (d *Diagram) SwapLines(lineID1, lineID2, op int) error {
+
+	// Find lines
+	i1, err := d.lineIndex(lineID1)
+	if err != nil {
+		return err
+	}
+	i2, err := d.lineIndex(lineID2)
+	if err != nil {
+		return err
+	}
+	if i1 == i2 {
+		return fmt.Errorf("can't swap line %d with itself", lineID1)
+	}
+
+	// Exchange points after operating point
+	before1, after1 := splitPoints(d.Lines[i1].Points, op)
+	before2, after2 := splitPoints(d.Lines[i2].Points, op)
+	if len(after1) == 0 && len(after2) == 0 {
+		return fmt.Errorf("lines %d and %d have no points at or after operating point %d", lineID1, lineID2, op)
+	}
+	d.Lines[i1].Points = append(before1, after2...)
+	d.Lines[i2].Points = append(before2, after1...)
+	d.Lines[i1].normalize()
+	d.Lines[i2].normalize()
+
+	// Remove lines left empty, starting with the later one so the index of
+	// the other line stays valid
+	for _, i := range []int{max(i1, i2), min(i1, i2)} {
+		if len(d.Lines[i].Points) == 0 {
+			d.Lines = slices.Delete(d.Lines, i, i+1)
+		}
+	}
+
+	d.flagLinks()
+	return nil
+}
+
+// Validate checks that each line has at most one point per operating point,
+// that each point has its line's ID, and that each mode is in at most one line.
+func (d *Diagram) Validate() error {
+	type opMode struct{ OP, Mode int }
+	lineIDs := map[int]bool{}
+	modeLines := map[opMode]int{}
+	for _, line := range d.Lines {
+		if lineIDs[line.ID] {
+			return fmt.Errorf("duplicate line ID %d", line.ID)
+		}
+		lineIDs[line.ID] = true
+		if err := checkPointOPs(line.Points); err != nil {
+			return fmt.Errorf("line %d: %w", line.ID, err)
+		}
+		for _, p := range line.Points {
+			if p.Line != line.ID {
+				return fmt.Errorf("point at operating point %d in line %d has line ID %d", p.OP, line.ID, p.Line)
+			}
+			if id, ok := modeLines[opMode{p.OP, p.Mode}]; ok {
+				return fmt.Errorf("mode %d at operating point %d is in lines %d and %d", p.Mode, p.OP, id, line.ID)
+			}
+			modeLines[opMode{p.OP, p.Mode}] = line.ID
+		}
+	}
+	return nil
+}
+
+// Relink recalculates the link scores of all points and the confidence
+// flags of all lines from the linearization results, using the options the
+// diagram was generated with. It's used after the lines have been edited.
+func (d *Diagram) Relink(OPs []lin.LinOP) error {
+
+	// Build mode sets from lines
+	modeSets := make([]*ModeSet, len(d.Lines))
+	for i, line := range d.Lines {
+		modeSets[i] = &ModeSet{ID: line.ID, Label: line.Label}
+		for _, p := range line.Points {
+			if p.OP < 0 || p.OP >= len(OPs) {
+				return fmt.Errorf("line %d: invalid operating point %d", line.ID, p.OP)
+			}
+			m := findMode(OPs[p.OP].Modes, p.Mode)
+			if m == nil {
+				return fmt.Errorf("line %d: mode %d not found at operating point %d", line.ID, p.Mode, p.OP)
+			}
+			modeSets[i].Modes = append(modeSets[i].Modes, m)
+		}
+	}
+
+	// Score links between consecutive modes
+	scores, err := scoreLinks(OPs, modeSets, d.Options)
+	if err != nil {
+		return err
+	}
+
+	// Update points with scores
+	for i, ms := range modeSets {
+		for j, m := range ms.Modes {
+			p := &d.Lines[i].Points[j]
+			p.Correlation = float32(scores[m].Correlation)
+			p.Weight = float32(scores[m].Weight)
+			p.RunnerUpMode = scores[m].RunnerUpMode
+			p.RunnerUpWeight = float32(scores[m].RunnerUpWeight)
+		}
+	}
+
+	d.flagLinks()
+	return nil
+}
+
+// flagLinks updates the low confidence and swap flags of the lines
+func (d *Diagram) flagLinks() {
+	flagLinks(d.Lines, d.Options.ConfidenceMargin)
+}
+
+// lineIndex returns the index of the line with the given ID
+func (d *Diagram) lineIndex(lineID int) (int, error) {
+	for i, line := range d.Lines {
+		if line.ID == lineID {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("line %d not found", lineID)
+}
+
+// nextLineID returns an ID greater than the ID of all lines
+func (d *Diagram) nextLineID() int {
+	id := 0
+	for _, line := range d.Lines {
+		id = max(id, line.ID+1)
+	}
+	return id
+}
+
+// normalize sorts the points by operating point and sets their line ID
+func (line *Line) normalize() {
+	slices.SortFunc(line.Points, func(a, b Point) int { return a.OP - b.OP })
+	for i := range line.Points {
+		line.Points[i].Line = line.ID
+	}
+}
+
+// splitPoints returns copies of the points before the operating point and
+// the points at or after it
+func splitPoints(points []Point, op int) ([]Point, []Point) {
+	before, after := []Point{}, []Point{}
+	for _, p := range points {
+		if p.OP < op {
+			before = append(before, p)
+		} else {
+			after = append(after, p)
+		}
+	}
+	return before, after
+}
+
+// checkPointOPs returns an error if more than one point has the same operating point
+func checkPointOPs(points []Point) error {
+	ops := map[int]bool{}
+	for _, p := range points {
+		if ops[p.OP] {
+			return fmt.Errorf("multiple points at operating point %d", p.OP)
+		}
+		ops[p.OP] = true
+	}
+	return nil
+}
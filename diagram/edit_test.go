@@ -0,0 +1,132 @@
+package diagram_test
+
+import (
+	"acdc/diagram"
+	"testing"
+)
+
+// lineOPs returns the operating points of each line's points by line ID
+func lineOPs(d *diagram.Diagram) map[int][]int {
+	ops := map[int][]int{}
+	for _, line := range d.Lines {
+		ops[line.ID] = []int{}
+		for _, p := range line.Points {
+			if p.Line != line.ID {
+				panic("point line ID not updated")
+			}
+			ops[line.ID] = append(ops[line.ID], p.OP)
+		}
+	}
+	return ops
+}
+
+func TestDiagramEdit(t *testing.T) {
+
+	d := newTestDiagram([]float32{5, 10, 15}, [][]float32{
+		{0.3, 0.32, 0.34},
+		{0.6, 0.7, 0.8},
+	})
+
+	// Split line 0 at second OP
+	newID, err := d.SplitLine(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := newID, 2; act != exp {
+		t.Fatalf("new line ID = %v, expected %v", act, exp)
+	}
+	if act := lineOPs(d); len(act[0]) != 1 || len(act[2]) != 2 {
+		t.Fatalf("line OPs after split = %v", act)
+	}
+	if _, err := d.SplitLine(0, 0); err == nil {
+		t.Fatal("expected error splitting line without points before OP")
+	}
+
+	// Lines with points at the same OP can't be merged
+	if err := d.MergeLines(1, 2); err == nil {
+		t.Fatal("expected error merging overlapping lines")
+	}
+
+	// Merge split line back into line 0
+	if err := d.MergeLines(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if act := lineOPs(d); len(d.Lines) != 2 || len(act[0]) != 3 {
+		t.Fatalf("line OPs after merge = %v", act)
+	}
+
+	// Swap lines from second OP onward
+	if err := d.SwapLines(0, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := d.Lines[0].Points[1].NaturalFreqHz, float32(0.7); act != exp {
+		t.Fatalf("line 0 frequency after swap = %v, expected %v", act, exp)
+	}
+	if act, exp := d.Lines[1].Points[2].NaturalFreqHz, float32(0.34); act != exp {
+		t.Fatalf("line 1 frequency after swap = %v, expected %v", act, exp)
+	}
+
+	// Swapping lines without points at or after the OP should fail
+	if err := d.SwapLines(0, 1, 3); err == nil {
+		t.Fatal("expected error swapping lines without points after OP")
+	}
+
+	// Point can't be moved to a line with a point at the same OP
+	if err := d.MovePoint(0, 1, 0); err == nil {
+		t.Fatal("expected error moving point to line with point at OP")
+	}
+
+	// Move first point of line 1 to a new split line
+	newID, err = d.SplitLine(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MovePoint(0, 1, newID); err != nil {
+		t.Fatal(err)
+	}
+	if act := lineOPs(d); len(act[newID]) != 3 || len(act[1]) != 2 {
+		t.Fatalf("line OPs after move = %v", act)
+	}
+	if err := d.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving the last point of a line should remove the line
+	if err := d.MovePoint(1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MovePoint(2, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if act := lineOPs(d); len(d.Lines) != 2 || len(act[0]) != 3 {
+		t.Fatalf("line OPs after moving all points = %v", act)
+	}
+
+	// Swapping a line that only has points after the OP should remove it
+	newID, err = d.SplitLine(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SwapLines(newID, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if act := lineOPs(d); len(d.Lines) != 2 || len(act[0]) != 3 {
+		t.Fatalf("line OPs after swapping into empty line = %v", act)
+	}
+	if err := d.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Point with a different line ID should fail validation
+	d.Lines[0].Points[0].Line = d.Lines[1].ID
+	if err := d.Validate(); err == nil {
+		t.Fatal("expected validation error for point line ID")
+	}
+	d.Lines[0].Points[0].Line = d.Lines[0].ID
+
+	// Mode in multiple lines should fail validation
+	d.Lines[0].Points[0].Mode = d.Lines[1].Points[0].Mode
+	if err := d.Validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
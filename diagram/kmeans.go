@@ -0,0 +1,123 @@
+package diagram
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// kMeans partitions the observations into k clusters using Lloyd's
+// algorithm with k-means++ initialization. The random number generator
+// is only used for initialization, so the result is determined by its state.
+// It returns the cluster index of each observation.
+func kMeans(obs [][]float64, k int, rng *rand.Rand, maxIter int) []int {
+
+	// Initialize cluster centers
+	centers := kMeansPlusPlus(obs, k, rng)
+
+	// Assign observations to nearest centers and update centers until
+	// assignments stop changing
+	assign := make([]int, len(obs))
+	for i := range assign {
+		assign[i] = -1
+	}
+	for iter := 0; iter < maxIter; iter++ {
+
+		// Assign each observation to the nearest center
+		changes := 0
+		for i, o := range obs {
+			c, _ := nearestCenter(o, centers)
+			if c != assign[i] {
+				assign[i] = c
+				changes++
+			}
+		}
+		if changes == 0 {
+			break
+		}
+
+		// Move centers to mean of assigned observations
+		counts := make([]int, k)
+		for c := range centers {
+			floats.Scale(0, centers[c])
+		}
+		for i, o := range obs {
+			floats.Add(centers[assign[i]], o)
+			counts[assign[i]]++
+		}
+		for c := range centers {
+			if counts[c] > 0 {
+				floats.Scale(1/float64(counts[c]), centers[c])
+			}
+		}
+
+		// Move the center of each empty cluster to the observation farthest
+		// from its assigned center. A reseeded observation is at its new
+		// center, so it isn't selected again for another empty cluster.
+		for c := range centers {
+			if counts[c] > 0 {
+				continue
+			}
+			far, farDist := 0, -1.0
+			for i, o := range obs {
+				if d := floats.Distance(o, centers[assign[i]], 2); d > farDist {
+					far, farDist = i, d
+				}
+			}
+			copy(centers[c], obs[far])
+			assign[far] = c
+		}
+	}
+
+	return assign
+}
+
+// kMeansPlusPlus selects k initial cluster centers from the observations.
+// The first center is chosen uniformly and each following center is chosen
+// with probability proportional to its squared distance from the nearest
+// existing center.
+func kMeansPlusPlus(obs [][]float64, k int, rng *rand.Rand) [][]float64 {
+
+	centers := make([][]float64, 0, k)
+	centers = append(centers, append([]float64{}, obs[rng.IntN(len(obs))]...))
+
+	dist2 := make([]float64, len(obs))
+	for len(centers) < k {
+
+		// Squared distance from each observation to nearest center
+		sum := 0.0
+		for i, o := range obs {
+			_, d := nearestCenter(o, centers)
+			dist2[i] = d * d
+			sum += dist2[i]
+		}
+
+		// If all observations coincide with centers, pick uniformly
+		next := rng.IntN(len(obs))
+		if sum > 0 {
+			r := rng.Float64() * sum
+			for i, d2 := range dist2 {
+				r -= d2
+				if r < 0 || i == len(obs)-1 {
+					next = i
+					break
+				}
+			}
+		}
+		centers = append(centers, append([]float64{}, obs[next]...))
+	}
+
+	return centers
+}
+
+// nearestCenter returns the index of and distance to the center nearest to the observation
+func nearestCenter(o []float64, centers [][]float64) (int, float64) {
+	nearest, minDist := 0, math.Inf(1)
+	for c, center := range centers {
+		if d := floats.Distance(o, center, 2); d < minDist {
+			nearest, minDist = c, d
+		}
+	}
+	return nearest, minDist
+}
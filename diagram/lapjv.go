@@ -0,0 +1,271 @@
+package diagram
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Linear assignment with floating point costs using the shortest augmenting
+// path algorithm of Jonker and Volgenant, extended to rectangular matrices.
+// https://doi.org/10.1007/BF02278710
+// https://doi.org/10.1109/TAES.2016.140952
+
+// LinearAssignment returns the (row, column) pairs which minimize the total
+// cost of assigning rows to columns, sorted by row. The cost matrix may be
+// rectangular, in which case min(rows, columns) pairs are returned. Pairs
+// with a cost of +Inf are forbidden and never assigned. If not every row can
+// be assigned without using a forbidden pair, the result has the most pairs
+// possible and the minimum total cost of all assignments with that many.
+func LinearAssignment(cost [][]float64) ([][2]int, error) {
+
+	// Check matrix size
+	numRows := len(cost)
+	if numRows == 0 {
+		return [][2]int{}, nil
+	}
+	numCols := len(cost[0])
+	for i, row := range cost {
+		if len(row) != numCols {
+			return nil, fmt.Errorf("cost matrix row %d has %d columns, expected %d", i, len(row), numCols)
+		}
+	}
+
+	// Convert matrix to candidate lists, leaving out forbidden pairs
+	rows := make([][]AssignmentCandidate, numRows)
+	for i, row := range cost {
+		rows[i] = make([]AssignmentCandidate, 0, numCols)
+		for j, c := range row {
+			if !math.IsInf(c, 1) {
+				rows[i] = append(rows[i], AssignmentCandidate{Col: j, Cost: c})
+			}
+		}
+	}
+
+	return SparseLinearAssignment(rows, numCols)
+}
+
+// AssignmentCandidate is a column which may be assigned to a row and the
+// cost of the assignment.
+type AssignmentCandidate struct {
+	Col  int
+	Cost float64
+}
+
+// SparseLinearAssignment is LinearAssignment for a sparse cost matrix. Each
+// row lists the columns it may be assigned to, all other pairs are
+// forbidden. The time to solve depends on the number of candidates rather
+// than the size of the matrix, so large problems where each row has few
+// candidates are solved quickly.
+func SparseLinearAssignment(rows [][]AssignmentCandidate, numCols int) ([][2]int, error) {
+
+	// Check candidate columns and costs
+	numRows := len(rows)
+	for i, row := range rows {
+		for _, c := range row {
+			if c.Col < 0 || c.Col >= numCols {
+				return nil, fmt.Errorf("invalid column %d at row %d, expected 0-%d", c.Col, i, numCols-1)
+			}
+			if math.IsNaN(c.Cost) || math.IsInf(c.Cost, -1) {
+				return nil, fmt.Errorf("invalid cost %v at row %d, column %d", c.Cost, i, c.Col)
+			}
+		}
+	}
+	if numRows == 0 || numCols == 0 {
+		return [][2]int{}, nil
+	}
+
+	// Solver requires at least as many columns as rows, transpose if needed
+	transposed := numRows > numCols
+	if transposed {
+		t := make([][]AssignmentCandidate, numCols)
+		for i, row := range rows {
+			for _, c := range row {
+				t[c.Col] = append(t[c.Col], AssignmentCandidate{Col: i, Cost: c.Cost})
+			}
+		}
+		rows = t
+		numRows, numCols = numCols, numRows
+	}
+
+	// Give each row its own unassigned column with a cost greater than the
+	// difference in total cost between any two assignments, so the most rows
+	// possible are assigned before the total cost is minimized. Rows assigned
+	// to their unassigned column are left out of the result.
+	unassignedCost := 1.0
+	for _, row := range rows {
+		lo, hi := 0.0, 0.0
+		for _, c := range row {
+			if !math.IsInf(c.Cost, 1) {
+				lo, hi = min(lo, c.Cost), max(hi, c.Cost)
+			}
+		}
+		unassignedCost += hi - lo
+	}
+	extRows := make([][]AssignmentCandidate, numRows)
+	for i, row := range rows {
+		extRows[i] = append(make([]AssignmentCandidate, 0, len(row)+1), row...)
+		extRows[i] = append(extRows[i], AssignmentCandidate{Col: numCols + i, Cost: unassignedCost})
+	}
+	numExtCols := numCols + numRows
+
+	s := lapSolver{
+		rows:     extRows,
+		u:        make([]float64, numRows),
+		v:        make([]float64, numExtCols),
+		shortest: make([]float64, numExtCols),
+		path:     make([]int, numExtCols),
+		col4row:  make([]int, numRows),
+		row4col:  make([]int, numExtCols),
+		rowSeen:  make([]bool, numRows),
+		colSeen:  make([]bool, numExtCols),
+	}
+	for i := range s.col4row {
+		s.col4row[i] = -1
+	}
+	for j := range s.row4col {
+		s.row4col[j] = -1
+		s.shortest[j] = math.Inf(1)
+	}
+
+	// Assign rows one at a time along the shortest augmenting path
+	for curRow := range numRows {
+		s.augment(curRow)
+	}
+
+	// Collect assigned pairs in original orientation
+	pairs := [][2]int{}
+	for i, j := range s.col4row {
+		if j < 0 || j >= numCols {
+			continue
+		}
+		if transposed {
+			pairs = append(pairs, [2]int{j, i})
+		} else {
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+	slices.SortFunc(pairs, func(a, b [2]int) int { return a[0] - b[0] })
+
+	return pairs, nil
+}
+
+// lapSolver holds the state of the shortest augmenting path solver.
+// u and v are the dual variables (potentials) of the rows and columns.
+// Only the rows and columns reached by a search are visited, and tracked
+// in seenRows and reached, so the cost of a search doesn't depend on the
+// size of the problem.
+type lapSolver struct {
+	rows     [][]AssignmentCandidate
+	u, v     []float64
+	shortest []float64
+	path     []int
+	col4row  []int
+	row4col  []int
+	rowSeen  []bool
+	colSeen  []bool
+	seenRows []int
+	reached  []int // Columns with a path from the current row
+	pending  []int // Reached columns which haven't been seen
+}
+
+// augment finds the shortest augmenting path from the row to an unassigned
+// column, updates the dual variables, and assigns the row along the path.
+// If no path exists without forbidden pairs, the row is left unassigned.
+func (s *lapSolver) augment(curRow int) {
+
+	// Find shortest path
+	sink, minVal := s.shortestPath(curRow)
+	if sink < 0 {
+		return
+	}
+
+	// Update dual variables
+	s.u[curRow] += minVal
+	for _, i := range s.seenRows {
+		if i != curRow {
+			s.u[i] += minVal - s.shortest[s.col4row[i]]
+		}
+	}
+	for _, j := range s.reached {
+		if s.colSeen[j] {
+			s.v[j] -= minVal - s.shortest[j]
+		}
+	}
+
+	// Swap assignments along the path back to the current row
+	for j := sink; ; {
+		i := s.path[j]
+		s.row4col[j] = i
+		s.col4row[i], j = j, s.col4row[i]
+		if i == curRow {
+			break
+		}
+	}
+}
+
+// shortestPath runs Dijkstra's algorithm on the reduced costs from the row
+// and returns the unassigned column at the end of the path and its length.
+// It returns -1 if all unassigned columns are unreachable.
+func (s *lapSolver) shortestPath(curRow int) (int, float64) {
+
+	// Reset rows and columns visited by the previous search
+	for _, j := range s.reached {
+		s.shortest[j] = math.Inf(1)
+		s.colSeen[j] = false
+	}
+	for _, i := range s.seenRows {
+		s.rowSeen[i] = false
+	}
+	s.reached, s.seenRows, s.pending = s.reached[:0], s.seenRows[:0], s.pending[:0]
+
+	minVal := 0.0
+	for i := curRow; ; {
+		s.rowSeen[i] = true
+		s.seenRows = append(s.seenRows, i)
+
+		// Update path lengths through row
+		for _, c := range s.rows[i] {
+			j := c.Col
+			if s.colSeen[j] {
+				continue
+			}
+			if r := minVal + c.Cost - s.u[i] - s.v[j]; r < s.shortest[j] {
+				if math.IsInf(s.shortest[j], 1) {
+					s.reached = append(s.reached, j)
+					s.pending = append(s.pending, j)
+				}
+				s.path[j] = i
+				s.shortest[j] = r
+			}
+		}
+
+		// Find the closest column, preferring unassigned columns when
+		// lengths are equal
+		index, lowest := -1, math.Inf(1)
+		for k, j := range s.pending {
+			if s.shortest[j] < lowest || (s.shortest[j] == lowest && s.row4col[j] < 0) {
+				index, lowest = k, s.shortest[j]
+			}
+		}
+
+		// If no reachable columns remain, row can't be assigned
+		if index < 0 {
+			return -1, 0
+		}
+		minVal = lowest
+
+		// Mark column as seen and remove it from pending
+		j := s.pending[index]
+		s.colSeen[j] = true
+		s.pending[index] = s.pending[len(s.pending)-1]
+		s.pending = s.pending[:len(s.pending)-1]
+
+		// If column is unassigned the path is complete,
+		// otherwise continue from the row assigned to it
+		if s.row4col[j] < 0 {
+			return j, minVal
+		}
+		i = s.row4col[j]
+	}
+}
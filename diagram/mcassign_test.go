@@ -2,6 +2,9 @@ package diagram_test
 
 import (
 	"acdc/diagram"
+	"math"
+	"math/rand/v2"
+	"reflect"
 	"testing"
 )
 
@@ -51,3 +54,145 @@ func TestMinCostAssignment(t *testing.T) {
 		}
 	}
 }
+
+func TestLinearAssignment(t *testing.T) {
+
+	inf := math.Inf(1)
+
+	testCases := []struct {
+		name    string
+		cost    [][]float64
+		pathExp [][2]int
+	}{
+		{
+			name:    "square",
+			cost:    [][]float64{{0.5, 0.1, 0.9}, {0.2, 0.8, 0.3}, {0.7, 0.6, 0.05}},
+			pathExp: [][2]int{{0, 1}, {1, 0}, {2, 2}},
+		},
+		{
+			name:    "more columns",
+			cost:    [][]float64{{0.9, 0.2, 0.5, 0.1}, {0.3, 0.1, 0.8, 0.2}},
+			pathExp: [][2]int{{0, 3}, {1, 1}},
+		},
+		{
+			name:    "more rows",
+			cost:    [][]float64{{0.9, 0.2}, {0.3, 0.1}, {0.1, 0.8}},
+			pathExp: [][2]int{{1, 1}, {2, 0}},
+		},
+		{
+			name:    "forbidden",
+			cost:    [][]float64{{0.1, inf, 0.4}, {0.2, inf, inf}, {inf, inf, inf}},
+			pathExp: [][2]int{{0, 2}, {1, 0}},
+		},
+		{
+			name:    "negative",
+			cost:    [][]float64{{-1, -2}, {-3, -5}},
+			pathExp: [][2]int{{0, 0}, {1, 1}},
+		},
+	}
+
+	for _, tc := range testCases {
+		path, err := diagram.LinearAssignment(tc.cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(path, tc.pathExp) {
+			t.Fatalf("%s: path = %v, expected %v", tc.name, path, tc.pathExp)
+		}
+	}
+
+	// Compare total cost with exhaustive search of random matrices
+	rng := rand.New(rand.NewPCG(1, 2))
+	for n := 1; n <= 6; n++ {
+		cost := make([][]float64, n)
+		for i := range cost {
+			cost[i] = make([]float64, n)
+			for j := range cost[i] {
+				cost[i][j] = rng.Float64()
+			}
+		}
+		path, err := diagram.LinearAssignment(cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := 0.0
+		for _, p := range path {
+			total += cost[p[0]][p[1]]
+		}
+		if _, exp := minTotalCost(cost, 0, make([]bool, n)); math.Abs(total-exp) > 1e-12 {
+			t.Fatalf("n = %d: total cost = %v, expected %v", n, total, exp)
+		}
+	}
+
+	// NaN costs are invalid
+	if _, err := diagram.LinearAssignment([][]float64{{math.NaN()}}); err == nil {
+		t.Fatal("expected error for NaN cost")
+	}
+}
+
+func TestSparseLinearAssignment(t *testing.T) {
+
+	// Sparse and dense solutions of random matrices with forbidden pairs
+	// should have the most pairs possible with the minimum total cost
+	rng := rand.New(rand.NewPCG(3, 4))
+	for n := 1; n <= 8; n++ {
+		numCols := max(n+rng.IntN(3)-1, 1)
+		cost := diagram.NewFloatMatrix(n, numCols, math.Inf(1))
+		rows := make([][]diagram.AssignmentCandidate, n)
+		for i := range cost {
+			for j := range cost[i] {
+				if rng.Float64() < 0.4 {
+					cost[i][j] = rng.Float64()
+					rows[i] = append(rows[i], diagram.AssignmentCandidate{Col: j, Cost: cost[i][j]})
+				}
+			}
+		}
+		expPairs, expCost := minTotalCost(cost, 0, make([]bool, numCols))
+		dense, err := diagram.LinearAssignment(cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sparse, err := diagram.SparseLinearAssignment(rows, numCols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, pairs := range map[string][][2]int{"dense": dense, "sparse": sparse} {
+			total := 0.0
+			for _, p := range pairs {
+				total += cost[p[0]][p[1]]
+			}
+			if len(pairs) != expPairs || math.Abs(total-expCost) > 1e-12 {
+				t.Fatalf("n = %d: %s %v has %d pairs (cost %v), expected %d pairs (cost %v)",
+					n, name, pairs, len(pairs), total, expPairs, expCost)
+			}
+		}
+	}
+
+	// Columns outside the matrix are invalid
+	if _, err := diagram.SparseLinearAssignment([][]diagram.AssignmentCandidate{{{Col: 2}}}, 2); err == nil {
+		t.Fatal("expected error for invalid column")
+	}
+}
+
+// minTotalCost returns the number of pairs and the total cost of the
+// assignment with the most pairs and, among those, the minimum total cost by
+// checking every assignment of rows from i onward to unused columns. Rows may
+// be left unassigned and forbidden (+Inf) pairs are skipped.
+func minTotalCost(cost [][]float64, i int, used []bool) (int, float64) {
+	if i == len(cost) {
+		return 0, 0
+	}
+	bestPairs, bestCost := minTotalCost(cost, i+1, used)
+	for j := range used {
+		if !used[j] && !math.IsInf(cost[i][j], 1) {
+			used[j] = true
+			pairs, total := minTotalCost(cost, i+1, used)
+			used[j] = false
+			pairs, total = pairs+1, total+cost[i][j]
+			if pairs > bestPairs || (pairs == bestPairs && total < bestCost) {
+				bestPairs, bestCost = pairs, total
+			}
+		}
+	}
+	return bestPairs, bestCost
+}
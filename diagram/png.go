@@ -0,0 +1,264 @@
+package diagram
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// pngFont returns the Go Regular font used for text in PNG images, which is
+// parsed once when first used
+var pngFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// pngCanvas draws diagram elements into an image. Shapes are filled as
+// anti-aliased vector paths and text is drawn with the Go Regular font.
+type pngCanvas struct {
+	img      *image.RGBA
+	clipRect image.Rectangle
+	z        *vector.Rasterizer
+	font     *opentype.Font
+	faces    map[float64]font.Face // Font faces by size
+	err      error                 // First error while drawing, returned by write
+}
+
+func newPNGCanvas(width, height int) (*pngCanvas, error) {
+	f, err := pngFont()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing font: %w", err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	return &pngCanvas{
+		img:      img,
+		clipRect: img.Bounds(),
+		z:        vector.NewRasterizer(0, 0),
+		font:     f,
+		faces:    map[float64]font.Face{},
+	}, nil
+}
+
+func (c *pngCanvas) rect(x, y, w, h float64, fill color.NRGBA) {
+	c.fillPolygons([][][2]float64{{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}}, fill)
+}
+
+func (c *pngCanvas) polyline(xs, ys []float64, stroke color.NRGBA, width float64, dash []float64) {
+
+	// Build polygons covering each dash of the line: a quad for each segment
+	// and a circle at each joint so segments are joined with round corners.
+	// All polygons have the same orientation so overlaps are drawn once.
+	halfW := width / 2
+	polys := [][][2]float64{}
+	for _, run := range dashRuns(xs, ys, dash) {
+		for i := 1; i < len(run); i++ {
+			p0, p1 := run[i-1], run[i]
+			segLen := math.Hypot(p1[0]-p0[0], p1[1]-p0[1])
+			if segLen == 0 {
+				continue
+			}
+			nx, ny := -(p1[1]-p0[1])/segLen*halfW, (p1[0]-p0[0])/segLen*halfW
+			polys = append(polys, [][2]float64{
+				{p0[0] - nx, p0[1] - ny},
+				{p1[0] - nx, p1[1] - ny},
+				{p1[0] + nx, p1[1] + ny},
+				{p0[0] + nx, p0[1] + ny},
+			})
+			if i < len(run)-1 {
+				polys = append(polys, circlePolygon(p1[0], p1[1], halfW))
+			}
+		}
+	}
+
+	c.fillPolygons(polys, stroke)
+}
+
+// dashRuns splits the polyline into the parts drawn by the dash pattern,
+// which alternates between dash and gap lengths in pixels
+func dashRuns(xs, ys []float64, dash []float64) [][][2]float64 {
+
+	// Collect points of polyline
+	points := make([][2]float64, len(xs))
+	for i := range xs {
+		points[i] = [2]float64{xs[i], ys[i]}
+	}
+
+	// If no dash pattern, line is drawn in one run
+	period := 0.0
+	for _, v := range dash {
+		period += max(v, 0)
+	}
+	if period == 0 || len(points) < 2 {
+		return [][][2]float64{points}
+	}
+
+	// Pattern with an odd number of values is repeated, as in SVG
+	if len(dash)%2 == 1 {
+		dash = append(dash[:len(dash):len(dash)], dash...)
+	}
+
+	// Walk along segments, starting and ending runs at dash boundaries
+	runs := [][][2]float64{}
+	k, left := 0, max(dash[0], 0) // Index of dash value and length left in it
+	run := [][2]float64{points[0]}
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		segLen := math.Hypot(p1[0]-p0[0], p1[1]-p0[1])
+		dist := 0.0 // Distance along segment
+		for segLen-dist > left {
+			dist += left
+			t := dist / segLen
+			p := [2]float64{p0[0] + t*(p1[0]-p0[0]), p0[1] + t*(p1[1]-p0[1])}
+			if k%2 == 0 {
+				runs = append(runs, append(run, p))
+				run = nil
+			} else {
+				run = [][2]float64{p}
+			}
+			k = (k + 1) % len(dash)
+			left = max(dash[k], 0)
+		}
+		left -= segLen - dist
+		if k%2 == 0 {
+			run = append(run, p1)
+		}
+	}
+	if len(run) > 1 {
+		runs = append(runs, run)
+	}
+
+	return runs
+}
+
+func (c *pngCanvas) circle(x, y, r float64, fill color.NRGBA) {
+	c.fillPolygons([][][2]float64{circlePolygon(x, y, r)}, fill)
+}
+
+// circlePolygon returns the vertices of a polygon approximating the circle,
+// with the same orientation as the quads of polyline
+func circlePolygon(x, y, r float64) [][2]float64 {
+	n := max(12, int(math.Ceil(4*r)))
+	points := make([][2]float64, n)
+	for i := range points {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = [2]float64{x + r*math.Cos(a), y + r*math.Sin(a)}
+	}
+	return points
+}
+
+// fillPolygons fills the area covered by the polygons, limited to the clip
+// rectangle. The rasterizer only covers the polygons' bounding box, so the
+// cost of drawing a shape depends on its size rather than the image size.
+func (c *pngCanvas) fillPolygons(polys [][][2]float64, fill color.NRGBA) {
+
+	// Get bounding box of polygons in pixels, limited to the clip rectangle
+	r := image.Rectangle{}
+	for _, poly := range polys {
+		for _, p := range poly {
+			r = r.Union(image.Rect(int(math.Floor(p[0])), int(math.Floor(p[1])),
+				int(math.Floor(p[0]))+1, int(math.Floor(p[1]))+1))
+		}
+	}
+	r = r.Intersect(c.clipRect)
+	if r.Empty() {
+		return
+	}
+
+	// Add polygons relative to the bounding box and draw them
+	c.z.Reset(r.Dx(), r.Dy())
+	for _, poly := range polys {
+		for i, p := range poly {
+			x, y := float32(p[0]-float64(r.Min.X)), float32(p[1]-float64(r.Min.Y))
+			if i == 0 {
+				c.z.MoveTo(x, y)
+			} else {
+				c.z.LineTo(x, y)
+			}
+		}
+		c.z.ClosePath()
+	}
+	c.z.Draw(c.img, r, image.NewUniform(fill), image.Point{})
+}
+
+func (c *pngCanvas) text(x, y float64, s string, size float64, anchor textAnchor, vertical bool, fill color.NRGBA) {
+
+	// Get font face for size
+	face, ok := c.faces[size]
+	if !ok {
+		var err error
+		face, err = opentype.NewFace(c.font, &opentype.FaceOptions{Size: size, DPI: 72})
+		if err != nil {
+			c.err = cmp.Or(c.err, fmt.Errorf("error creating font face: %w", err))
+			return
+		}
+		c.faces[size] = face
+	}
+
+	// Draw text into a mask with the baseline at the font ascent
+	m := face.Metrics()
+	width := font.MeasureString(face, s).Ceil()
+	height := m.Ascent.Ceil() + m.Descent.Ceil()
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(0, m.Ascent.Ceil())}
+	d.DrawString(s)
+
+	// Get offset of mask from position along the text direction from the
+	// anchor, and across it so the text is centered vertically on position
+	along := 0.0
+	switch anchor {
+	case anchorMiddle:
+		along = -float64(width) / 2
+	case anchorEnd:
+		along = -float64(width)
+	}
+	across := (float64(m.Ascent)-float64(m.Descent))/128 - float64(m.Ascent.Ceil())
+
+	// Rotate mask counterclockwise if vertical, so the text reads upward
+	origin := image.Pt(int(math.Round(x+along)), int(math.Round(y+across)))
+	if vertical {
+		rot := image.NewAlpha(image.Rect(0, 0, height, width))
+		for v := range height {
+			for u := range width {
+				rot.Pix[rot.PixOffset(v, width-1-u)] = mask.Pix[mask.PixOffset(u, v)]
+			}
+		}
+		mask = rot
+		origin = image.Pt(int(math.Round(x+across)), int(math.Round(y-along))-width)
+	}
+
+	// Draw text color through mask, limited to the clip rectangle
+	r := mask.Rect.Add(origin)
+	rc := r.Intersect(c.clipRect)
+	draw.DrawMask(c.img, rc, image.NewUniform(fill), image.Point{}, mask, rc.Min.Sub(r.Min), draw.Over)
+}
+
+func (c *pngCanvas) clip(x, y, w, h float64) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	c.clipRect = r.Intersect(c.img.Bounds())
+}
+
+func (c *pngCanvas) unclip() {
+	c.clipRect = c.img.Bounds()
+}
+
+// write writes the PNG image
+func (c *pngCanvas) write(w io.Writer) error {
+	if c.err != nil {
+		return c.err
+	}
+	if err := png.Encode(w, c.img); err != nil {
+		return fmt.Errorf("error writing PNG: %w", err)
+	}
+	return nil
+}
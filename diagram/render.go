@@ -0,0 +1,467 @@
+package diagram
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RenderOptions controls the size and content of rendered diagrams
+type RenderOptions struct {
+	Width      int    `json:"Width"`      // Image width in pixels
+	Height     int    `json:"Height"`     // Image height in pixels
+	Title      string `json:"Title"`      // Title above the subplots, omitted if empty
+	Units      Units  `json:"Units"`      // Units of frequency and damping values
+	WindSpeed  bool   `json:"WindSpeed"`  // Plot over wind speed if the diagram has wind speeds
+	Harmonics  []int  `json:"Harmonics"`  // Rotor harmonics (nP) drawn on the frequency subplot
+	ShowHidden bool   `json:"ShowHidden"` // Draw hidden lines faded instead of omitting them
+}
+
+// NewRenderOptions returns render options with the default size and
+// the rotor harmonics shown in the app
+func NewRenderOptions() RenderOptions {
+	return RenderOptions{
+		Width:     1600,
+		Height:    800,
+		Units:     NewUnits(),
+		WindSpeed: true,
+		Harmonics: []int{1, 3, 6, 9, 12, 15},
+	}
+}
+
+// RenderFile renders the diagram to an SVG or PNG file based on the path's extension
+func (d *Diagram) RenderFile(path string, opts RenderOptions) error {
+
+	// Select renderer from extension
+	var render func(io.Writer, RenderOptions) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		render = d.RenderSVG
+	case ".png":
+		render = d.RenderPNG
+	default:
+		return fmt.Errorf("unsupported image format '%s', expected .svg or .png", filepath.Ext(path))
+	}
+
+	// Create file
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating image file '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	// Render diagram to file
+	if err := render(f, opts); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// RenderSVG writes the diagram as an SVG image with frequency and damping subplots
+func (d *Diagram) RenderSVG(w io.Writer, opts RenderOptions) error {
+	c := newSVGCanvas(opts.Width, opts.Height)
+	if err := d.render(c, opts); err != nil {
+		return err
+	}
+	return c.write(w)
+}
+
+// RenderPNG writes the diagram as a PNG image with frequency and damping subplots
+func (d *Diagram) RenderPNG(w io.Writer, opts RenderOptions) error {
+	c, err := newPNGCanvas(opts.Width, opts.Height)
+	if err != nil {
+		return err
+	}
+	if err := d.render(c, opts); err != nil {
+		return err
+	}
+	return c.write(w)
+}
+
+//------------------------------------------------------------------------------
+// Canvas
+//------------------------------------------------------------------------------
+
+// canvas is implemented by the SVG and PNG renderers. Coordinates are in
+// pixels from the top left corner of the image.
+type canvas interface {
+	rect(x, y, w, h float64, fill color.NRGBA)
+	polyline(xs, ys []float64, stroke color.NRGBA, width float64, dash []float64)
+	circle(x, y, r float64, fill color.NRGBA)
+	text(x, y float64, s string, size float64, anchor textAnchor, vertical bool, fill color.NRGBA)
+	clip(x, y, w, h float64) // Clip following drawing to the rectangle
+	unclip()
+}
+
+// textAnchor is the horizontal alignment of text relative to its position.
+// Text is vertically centered on its position.
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// Font sizes and spacing of rendered diagrams in pixels
+const (
+	titleSize  = 24
+	labelSize  = 20
+	tickSize   = 16
+	legendSize = 16
+	padding    = 20
+)
+
+var (
+	colorBlack     = color.NRGBA{0, 0, 0, 255}
+	colorWhite     = color.NRGBA{255, 255, 255, 255}
+	colorGrid      = color.NRGBA{221, 221, 221, 255}
+	colorHarmonic  = color.NRGBA{112, 128, 144, 255} // slategray
+	harmonicDash   = []float64{4, 6}
+	defaultPalette = []color.NRGBA{
+		{31, 119, 180, 255}, {255, 127, 14, 255}, {44, 160, 44, 255},
+		{214, 39, 40, 255}, {148, 103, 189, 255}, {140, 86, 75, 255},
+		{227, 119, 194, 255}, {127, 127, 127, 255}, {188, 189, 34, 255},
+		{23, 190, 207, 255},
+	}
+)
+
+// textWidth returns the approximate width of the text in pixels
+func textWidth(s string, size float64) float64 {
+	return 0.6 * size * float64(len(s))
+}
+
+//------------------------------------------------------------------------------
+// Layout
+//------------------------------------------------------------------------------
+
+// renderLine is a diagram line ready to draw
+type renderLine struct {
+	label string
+	color color.NRGBA
+	dash  []float64
+	xs    []float64
+	freqs []float64
+	damps []float64
+}
+
+// subplot maps data values to pixels in its plot area
+type subplot struct {
+	x0, y0, x1, y1 float64 // Plot area in pixels
+	xMin, xMax     float64
+	yMin, yMax     float64
+	xTicks, yTicks []float64
+}
+
+func (sp *subplot) px(x float64) float64 {
+	return sp.x0 + (x-sp.xMin)/(sp.xMax-sp.xMin)*(sp.x1-sp.x0)
+}
+
+func (sp *subplot) py(y float64) float64 {
+	return sp.y1 - (y-sp.yMin)/(sp.yMax-sp.yMin)*(sp.y1-sp.y0)
+}
+
+// render draws the diagram on the canvas
+func (d *Diagram) render(c canvas, opts RenderOptions) error {
+
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return fmt.Errorf("invalid image size %dx%d", opts.Width, opts.Height)
+	}
+	W, H := float64(opts.Width), float64(opts.Height)
+
+	// Convert line values to units
+	s, err := d.Series(opts.Units)
+	if err != nil {
+		return err
+	}
+
+	// Get x values and label
+	useWind := opts.WindSpeed && d.HasWind
+	xLabel, xValues := "Rotor Speed (RPM)", d.RotSpeeds
+	if useWind {
+		xLabel, xValues = "Wind Speed (m/s)", d.WindSpeeds
+	}
+
+	// Collect lines to draw
+	lines := []renderLine{}
+	for i, sl := range s.Lines {
+		if sl.Hidden && !opts.ShowHidden {
+			continue
+		}
+		rl := renderLine{label: sl.Label}
+		var ok bool
+		if rl.color, ok = parseColor(d.Lines[i].Color); !ok {
+			rl.color = defaultPalette[i%len(defaultPalette)]
+		}
+		if sl.Hidden {
+			rl.color.A = 64
+		}
+		for _, v := range d.Lines[i].Dash {
+			rl.dash = append(rl.dash, float64(v))
+		}
+		for _, p := range sl.Points {
+			x := p.RotSpeed
+			if useWind {
+				x = p.WindSpeed
+			}
+			rl.xs = append(rl.xs, float64(x))
+			rl.freqs = append(rl.freqs, p.Frequency)
+			rl.damps = append(rl.damps, p.Damping)
+		}
+		lines = append(lines, rl)
+	}
+
+	// Fill background
+	c.rect(0, 0, W, H, colorWhite)
+
+	// Draw title
+	top := float64(padding)
+	if opts.Title != "" {
+		c.text(W/2, top+titleSize/2, opts.Title, titleSize, anchorMiddle, false, colorBlack)
+		top += 1.6 * titleSize
+	}
+
+	// Draw legend at bottom, getting its height
+	bottom := H - padding - drawLegend(c, lines, padding, H-padding, W-2*padding)
+
+	// Get range of x values
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	for _, x := range xValues {
+		xMin, xMax = min(xMin, float64(x)), max(xMax, float64(x))
+	}
+
+	// Layout subplots side by side, leaving room for tick and axis labels
+	plotW := (W - 3*padding) / 2
+	left := float64(4*labelSize + 2*tickSize)
+	for k, yLabel := range []string{s.FrequencyLabel, s.DampingLabel} {
+
+		// Get range of y values of lines
+		yMin, yMax := math.Inf(1), math.Inf(-1)
+		for _, rl := range lines {
+			ys := rl.freqs
+			if k == 1 {
+				ys = rl.damps
+			}
+			for _, y := range ys {
+				yMin, yMax = min(yMin, y), max(yMax, y)
+			}
+		}
+
+		x0 := padding + float64(k)*(plotW+padding)
+		sp := &subplot{
+			x0: x0 + left, y0: top,
+			x1: x0 + plotW, y1: bottom - 2*labelSize - 2*tickSize,
+		}
+		sp.xMin, sp.xMax, sp.xTicks = niceRange(xMin, xMax, false)
+		sp.yMin, sp.yMax, sp.yTicks = niceRange(yMin, yMax, true)
+		drawAxes(c, sp, xLabel, yLabel)
+
+		// Clip data to plot area
+		c.clip(sp.x0, sp.y0, sp.x1-sp.x0, sp.y1-sp.y0)
+
+		// Draw rotor harmonic lines on frequency subplot
+		if k == 0 {
+			for _, n := range opts.Harmonics {
+				xs, ys := make([]float64, len(xValues)), make([]float64, len(xValues))
+				for i := range xValues {
+					xs[i] = sp.px(float64(xValues[i]))
+					ys[i] = sp.py(float64(n) * s.RotorFreq[i])
+				}
+				c.polyline(xs, ys, colorHarmonic, 1.5, harmonicDash)
+				if last := len(ys) - 1; last >= 0 && ys[last] > sp.y0+tickSize && ys[last] < sp.y1 {
+					c.text(xs[last]-4, ys[last]-tickSize/2, strconv.Itoa(n)+"P", tickSize, anchorEnd, false, colorHarmonic)
+				}
+			}
+		}
+
+		// Draw lines with markers at points
+		for _, rl := range lines {
+			ys := rl.freqs
+			if k == 1 {
+				ys = rl.damps
+			}
+			pxs, pys := make([]float64, len(ys)), make([]float64, len(ys))
+			for i := range ys {
+				pxs[i], pys[i] = sp.px(rl.xs[i]), sp.py(ys[i])
+			}
+			c.polyline(pxs, pys, rl.color, 2, rl.dash)
+			for i := range pxs {
+				c.circle(pxs[i], pys[i], 3.5, rl.color)
+			}
+		}
+
+		c.unclip()
+	}
+
+	return nil
+}
+
+// drawAxes draws the plot area border, grid, ticks, and axis labels
+func drawAxes(c canvas, sp *subplot, xLabel, yLabel string) {
+
+	// Draw grid lines and tick labels
+	for _, x := range sp.xTicks {
+		px := sp.px(x)
+		c.polyline([]float64{px, px}, []float64{sp.y0, sp.y1}, colorGrid, 1, nil)
+		c.text(px, sp.y1+tickSize, formatTick(x, sp.xTicks), tickSize, anchorMiddle, false, colorBlack)
+	}
+	for _, y := range sp.yTicks {
+		py := sp.py(y)
+		c.polyline([]float64{sp.x0, sp.x1}, []float64{py, py}, colorGrid, 1, nil)
+		c.text(sp.x0-tickSize/2, py, formatTick(y, sp.yTicks), tickSize, anchorEnd, false, colorBlack)
+	}
+
+	// Draw border
+	c.polyline([]float64{sp.x0, sp.x1, sp.x1, sp.x0, sp.x0}, []float64{sp.y0, sp.y0, sp.y1, sp.y1, sp.y0}, colorBlack, 1, nil)
+
+	// Draw axis labels
+	c.text((sp.x0+sp.x1)/2, sp.y1+2*tickSize+labelSize, xLabel, labelSize, anchorMiddle, false, colorBlack)
+	c.text(sp.x0-3*tickSize-2*labelSize, (sp.y0+sp.y1)/2, yLabel, labelSize, anchorMiddle, true, colorBlack)
+}
+
+// drawLegend draws an entry for each line in rows above the bottom edge
+// and returns the height of the legend
+func drawLegend(c canvas, lines []renderLine, left, bottom, width float64) float64 {
+
+	const sample = 32 // Length of line sample in entry
+	rowH := 1.6 * legendSize
+
+	// Assign entries to rows
+	rows := [][]int{{}}
+	rowW := 0.0
+	for i, rl := range lines {
+		w := sample + 8 + textWidth(rl.label, legendSize) + 2*padding
+		if rowW+w > width && len(rows[len(rows)-1]) > 0 {
+			rows = append(rows, []int{})
+			rowW = 0
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], i)
+		rowW += w
+	}
+	if len(lines) == 0 {
+		return 0
+	}
+
+	// Draw entries
+	height := float64(len(rows)) * rowH
+	for r, row := range rows {
+		x := left
+		y := bottom - height + (float64(r)+0.5)*rowH
+		for _, i := range row {
+			rl := lines[i]
+			c.polyline([]float64{x, x + sample}, []float64{y, y}, rl.color, 3, rl.dash)
+			c.circle(x+sample/2, y, 3.5, rl.color)
+			c.text(x+sample+8, y, rl.label, legendSize, anchorStart, false, colorBlack)
+			x += sample + 8 + textWidth(rl.label, legendSize) + 2*padding
+		}
+	}
+
+	return height + padding
+}
+
+// niceRange returns a range containing the values and round tick values
+// within it. If expand is true, the range is padded and expanded to the
+// nearest ticks, otherwise it's the range of the values.
+func niceRange(vMin, vMax float64, expand bool) (float64, float64, []float64) {
+
+	// Handle empty and constant ranges
+	if math.IsInf(vMin, 0) || math.IsInf(vMax, 0) {
+		vMin, vMax = 0, 1
+	}
+	if vMax-vMin < 1e-9*max(math.Abs(vMin), math.Abs(vMax), 1) {
+		pad := max(math.Abs(vMin)*0.05, 0.5)
+		vMin, vMax = vMin-pad, vMax+pad
+	}
+
+	// Pad range so values aren't drawn on the border
+	if expand {
+		pad := 0.05 * (vMax - vMin)
+		vMin, vMax = vMin-pad, vMax+pad
+	}
+
+	// Select step of 1, 2, or 5 times a power of ten for about 6 ticks
+	raw := (vMax - vMin) / 6
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag
+	for _, m := range []float64{1, 2, 5, 10} {
+		if step = m * mag; step >= raw {
+			break
+		}
+	}
+
+	// Get ticks at multiples of step within range
+	lo, hi := math.Ceil(vMin/step-1e-9)*step, math.Floor(vMax/step+1e-9)*step
+	if expand {
+		lo, hi = math.Floor(vMin/step+1e-9)*step, math.Ceil(vMax/step-1e-9)*step
+		vMin, vMax = lo, hi
+	}
+	ticks := []float64{}
+	for i := 0; lo+float64(i)*step <= hi+step/2; i++ {
+		ticks = append(ticks, lo+float64(i)*step)
+	}
+
+	return vMin, vMax, ticks
+}
+
+// formatTick formats the tick value with the precision needed to
+// distinguish it from the other ticks
+func formatTick(v float64, ticks []float64) string {
+	decimals := 0
+	if len(ticks) > 1 {
+		decimals = max(0, int(math.Ceil(-math.Log10(ticks[1]-ticks[0])-1e-9)))
+	}
+	if math.Abs(v) < 1e-12 {
+		v = 0
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// parseColor parses a hex color (#rgb or #rrggbb) or a basic CSS color name
+func parseColor(s string) (color.NRGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+	if !strings.HasPrefix(s, "#") {
+		return color.NRGBA{}, false
+	}
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, true
+}
+
+var namedColors = map[string]color.NRGBA{
+	"black":     {0, 0, 0, 255},
+	"white":     {255, 255, 255, 255},
+	"red":       {255, 0, 0, 255},
+	"green":     {0, 128, 0, 255},
+	"blue":      {0, 0, 255, 255},
+	"orange":    {255, 165, 0, 255},
+	"purple":    {128, 0, 128, 255},
+	"brown":     {165, 42, 42, 255},
+	"gray":      {128, 128, 128, 255},
+	"grey":      {128, 128, 128, 255},
+	"cyan":      {0, 255, 255, 255},
+	"magenta":   {255, 0, 255, 255},
+	"yellow":    {255, 255, 0, 255},
+	"navy":      {0, 0, 128, 255},
+	"teal":      {0, 128, 128, 255},
+	"olive":     {128, 128, 0, 255},
+	"maroon":    {128, 0, 0, 255},
+	"slategray": {112, 128, 144, 255},
+}
@@ -0,0 +1,104 @@
+package diagram_test
+
+import (
+	"acdc/diagram"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagramRender(t *testing.T) {
+
+	d := newTestDiagram([]float32{5, 10, 15}, [][]float32{
+		{0.3, 0.32, 0.34},
+		{0.6, 0.7, 0.8},
+		{1.1, 1.2, 1.3},
+	})
+	d.Lines[0].Label = "Tower FA"
+	d.Lines[1].Color = "#00ff00"
+	d.Lines[1].Dash = []int{6, 4}
+	d.Lines[2].Label = "Hidden Mode"
+	d.Lines[2].Hidden = true
+
+	opts := diagram.NewRenderOptions()
+	opts.Title = "Campbell Diagram"
+
+	// Render SVG and check that it is valid XML with expected text
+	buf := &bytes.Buffer{}
+	if err := d.RenderSVG(buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("invalid SVG: %v", err)
+			}
+			break
+		}
+	}
+	svg := buf.String()
+	for _, s := range []string{"Campbell Diagram", "Tower FA", "Natural Frequency (Hz)", "Damping Ratio (-)", "3P", `stroke-dasharray="6,4"`} {
+		if !strings.Contains(svg, s) {
+			t.Fatalf("SVG doesn't contain %q", s)
+		}
+	}
+	if strings.Contains(svg, "Hidden Mode") {
+		t.Fatal("SVG contains hidden line")
+	}
+
+	// Hidden lines should be drawn when requested
+	opts.ShowHidden = true
+	buf.Reset()
+	if err := d.RenderSVG(buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Hidden Mode") {
+		t.Fatal("SVG doesn't contain hidden line")
+	}
+
+	// Render PNG to file and check image size
+	path := filepath.Join(t.TempDir(), "diagram.png")
+	opts.Width, opts.Height = 800, 400
+	if err := d.RenderFile(path, opts); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act := img.Bounds().Size(); act.X != 800 || act.Y != 400 {
+		t.Fatalf("PNG size = %v, expected 800x400", act)
+	}
+
+	// Non-ASCII text should be drawn with its own glyphs rather than a
+	// replacement character
+	renderPNG := func(title string) []byte {
+		buf := &bytes.Buffer{}
+		opts := diagram.NewRenderOptions()
+		opts.Title = title
+		if err := d.RenderPNG(buf, opts); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	if bytes.Equal(renderPNG("Damping ζ"), renderPNG("Damping ?")) {
+		t.Fatal("non-ASCII title rendered as replacement character")
+	}
+
+	// Unsupported formats should return an error
+	if err := d.RenderFile(filepath.Join(t.TempDir(), "diagram.gif"), opts); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,223 @@
+package diagram
+
+import (
+	"acdc/lin"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// MinStyleMatchScore is the score a line match must exceed for the styling
+// to be copied, when copying styles between diagrams or applying templates.
+const MinStyleMatchScore = 0.5
+
+// StyleTemplate contains the user styling of diagram lines with the
+// frequency curve of each line, so the styling can be applied to a new
+// diagram of the same turbine by matching the curves.
+type StyleTemplate struct {
+	HasWind bool        `json:"HasWind"` // Curves are over wind speed, otherwise rotor speed
+	Lines   []LineStyle `json:"Lines"`
+}
+
+type LineStyle struct {
+	Label         string    `json:"Label"`
+	Color         string    `json:"Color"`
+	Dash          []int     `json:"Dash"`
+	Hidden        bool      `json:"Hidden"`
+	Speeds        []float32 `json:"Speeds"`
+	NaturalFreqHz []float32 `json:"NaturalFreqHz"`
+}
+
+// StyleTemplate returns the styling of the diagram lines as a template
+func (d *Diagram) StyleTemplate() StyleTemplate {
+	t := StyleTemplate{HasWind: d.HasWind, Lines: []LineStyle{}}
+	for _, line := range d.Lines {
+		ls := lineStyle(line)
+		ls.Speeds = []float32{}
+		ls.NaturalFreqHz = []float32{}
+		for _, p := range line.Points {
+			ls.Speeds = append(ls.Speeds, pointSpeed(p, t.HasWind))
+			ls.NaturalFreqHz = append(ls.NaturalFreqHz, p.NaturalFreqHz)
+		}
+		t.Lines = append(t.Lines, ls)
+	}
+	return t
+}
+
+// ApplyStyleTemplate matches the template lines to the diagram lines by
+// comparing their frequency curves at the speeds of the diagram points and
+// copies the styling of matched template lines. Lines with a match score
+// at or below minScore aren't styled. It returns the number of lines styled.
+func (d *Diagram) ApplyStyleTemplate(t StyleTemplate, minScore float64) (int, error) {
+
+	if len(d.Lines) == 0 || len(t.Lines) == 0 {
+		return 0, nil
+	}
+
+	// Get frequency span of diagram and template to normalize differences
+	fMin, fMax := math.Inf(1), math.Inf(-1)
+	for _, line := range d.Lines {
+		for _, p := range line.Points {
+			fMin = min(fMin, float64(p.NaturalFreqHz))
+			fMax = max(fMax, float64(p.NaturalFreqHz))
+		}
+	}
+	for _, ls := range t.Lines {
+		for _, f := range ls.NaturalFreqHz {
+			fMin = min(fMin, float64(f))
+			fMax = max(fMax, float64(f))
+		}
+	}
+	fSpan := fMax - fMin
+	if !(fSpan > 0) {
+		fSpan = 1
+	}
+
+	// Calculate score of each template line with each diagram line
+	scores := make([][]float64, len(t.Lines))
+	for i, ls := range t.Lines {
+		scores[i] = make([]float64, len(d.Lines))
+		for j, line := range d.Lines {
+			if len(line.Points) == 0 {
+				continue
+			}
+
+			// Compare frequencies at points within the template curve's speed range,
+			// score is mean similarity reduced by the fraction of points outside range
+			sum := 0.0
+			for _, p := range line.Points {
+				if f, ok := ls.interpolate(pointSpeed(p, t.HasWind)); ok {
+					sum += max(1-math.Abs(float64(p.NaturalFreqHz-f))/fSpan, 0)
+				}
+			}
+			scores[i][j] = sum / float64(len(line.Points))
+		}
+	}
+
+	// Match template lines to diagram lines and copy styling
+	pairs, err := matchScores(scores)
+	if err != nil {
+		return 0, err
+	}
+	numStyled := 0
+	for _, pair := range pairs {
+		if scores[pair[0]][pair[1]] <= minScore {
+			continue
+		}
+		t.Lines[pair[0]].copyTo(&d.Lines[pair[1]])
+		numStyled++
+	}
+
+	return numStyled, nil
+}
+
+// CopyStyle copies the styling of the lines in the previous diagram to the
+// matching lines of this diagram. If both diagrams were generated from the
+// given linearization results, lines are matched by mode shape and frequency,
+// otherwise they are matched by frequency curve as a style template.
+func (d *Diagram) CopyStyle(prev *Diagram, OPs []lin.LinOP) error {
+
+	// If previous diagram doesn't belong to the results, apply it as a template
+	if !prev.MatchesResults(OPs) {
+		_, err := d.ApplyStyleTemplate(prev.StyleTemplate(), MinStyleMatchScore)
+		return err
+	}
+
+	// Match lines by mode shape and frequency
+	cmp, err := Compare(prev, d, OPs, OPs, CompareOptions{OPTolerance: 1e-3, MinScore: MinStyleMatchScore})
+	if err != nil {
+		return err
+	}
+	for _, lm := range cmp.Matches {
+		i := slices.IndexFunc(prev.Lines, func(l Line) bool { return l.ID == lm.Lines[0] })
+		j := slices.IndexFunc(d.Lines, func(l Line) bool { return l.ID == lm.Lines[1] })
+		lineStyle(prev.Lines[i]).copyTo(&d.Lines[j])
+	}
+
+	return nil
+}
+
+// MatchesResults returns true if every point in the diagram refers to a
+// mode with the same frequency in the linearization results
+func (d *Diagram) MatchesResults(OPs []lin.LinOP) bool {
+	if len(OPs) != len(d.RotSpeeds) {
+		return false
+	}
+	for _, line := range d.Lines {
+		for _, p := range line.Points {
+			if p.OP < 0 || p.OP >= len(OPs) {
+				return false
+			}
+			m := findMode(OPs[p.OP].Modes, p.Mode)
+			if m == nil || float32(m.NaturalFreqHz) != p.NaturalFreqHz {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lineStyle returns the styling of the line. Default labels aren't
+// included so they aren't copied to lines with a different ID.
+func lineStyle(line Line) LineStyle {
+	ls := LineStyle{
+		Label:  line.Label,
+		Color:  line.Color,
+		Dash:   line.Dash,
+		Hidden: line.Hidden,
+	}
+	if ls.Label == defaultLabel(line.ID) {
+		ls.Label = ""
+	}
+	return ls
+}
+
+// copyTo copies the styling to the line. Empty labels aren't copied so
+// the line keeps its default label.
+func (ls LineStyle) copyTo(line *Line) {
+	if ls.Label != "" {
+		line.Label = ls.Label
+	}
+	line.Color = ls.Color
+	line.Dash = slices.Clone(ls.Dash)
+	line.Hidden = ls.Hidden
+}
+
+// interpolate returns the frequency of the line style's curve at the speed
+// and false if the speed is outside the range of the curve
+func (ls LineStyle) interpolate(speed float32) (float32, bool) {
+	for i := range ls.Speeds {
+		if ls.Speeds[i] == speed {
+			return ls.NaturalFreqHz[i], true
+		}
+		if i > 0 && ls.Speeds[i-1] < speed && speed < ls.Speeds[i] {
+			s := (speed - ls.Speeds[i-1]) / (ls.Speeds[i] - ls.Speeds[i-1])
+			return ls.NaturalFreqHz[i-1] + s*(ls.NaturalFreqHz[i]-ls.NaturalFreqHz[i-1]), true
+		}
+	}
+	return 0, false
+}
+
+// pointSpeed returns the wind speed of the point if hasWind, otherwise the rotor speed
+func pointSpeed(p Point, hasWind bool) float32 {
+	if hasWind {
+		return p.WindSpeed
+	}
+	return p.RotSpeed
+}
+
+// defaultLabel returns the label given to a line when the diagram is generated
+func defaultLabel(lineID int) string {
+	return "Line " + strconv.Itoa(lineID+1)
+}
+
+// matchScores returns the row and column pairs which maximize the total score
+func matchScores(scores [][]float64) ([][2]int, error) {
+	cost := NewFloatMatrix(len(scores), len(scores[0]), 0)
+	for i := range cost {
+		for j := range cost[i] {
+			cost[i][j] = -scores[i][j]
+		}
+	}
+	return LinearAssignment(cost)
+}
@@ -0,0 +1,77 @@
+package diagram_test
+
+import (
+	"acdc/diagram"
+	"acdc/lin"
+	"testing"
+)
+
+func TestDiagramCopyStyle(t *testing.T) {
+
+	OPs := []lin.LinOP{
+		newTestOP(0, 5, []float64{1.0, 2.0}, [][]complex128{{1, 0}, {0, 1}}),
+		newTestOP(1, 10, []float64{1.1, 2.1}, [][]complex128{{1, 0}, {0, 1}}),
+	}
+
+	// Generate diagram and style the second line
+	prev, err := diagram.New(OPs, diagram.NewOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev.Lines[1].Label = "1st Tower FA"
+	prev.Lines[1].Color = "#ff0000"
+	prev.Lines[1].Dash = []int{4, 2}
+	prev.Lines[0].Hidden = true
+
+	// Regenerate with a frequency range which excludes the first mode
+	opts := diagram.NewOptions()
+	opts.MinFreq = 1.5
+	diag, err := diagram.New(OPs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := diag.CopyStyle(prev, OPs); err != nil {
+		t.Fatal(err)
+	}
+
+	line := diag.Lines[0]
+	if line.Label != "1st Tower FA" || line.Color != "#ff0000" || len(line.Dash) != 2 || line.Hidden {
+		t.Fatalf("line style not copied: %+v", line)
+	}
+}
+
+func TestDiagramStyleTemplate(t *testing.T) {
+
+	// Template from diagram with styled lines
+	d1 := newTestDiagram([]float32{5, 10, 15}, [][]float32{
+		{0.3, 0.32, 0.34},
+		{0.6, 0.7, 0.8},
+	})
+	d1.Lines[0].Label = "Tower"
+	d1.Lines[1].Label = "Blade"
+	d1.Lines[1].Color = "blue"
+	tmpl := d1.StyleTemplate()
+
+	// Diagram of another case with different operating points and line order
+	d2 := newTestDiagram([]float32{7.5, 12.5}, [][]float32{
+		{0.66, 0.76},
+		{1.5, 1.6},
+		{0.31, 0.33},
+	})
+	numStyled, err := d2.ApplyStyleTemplate(tmpl, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if act, exp := numStyled, 2; act != exp {
+		t.Fatalf("numStyled = %v, expected %v", act, exp)
+	}
+	for i, exp := range []string{"Blade", "B", "Tower"} {
+		if act := d2.Lines[i].Label; act != exp {
+			t.Fatalf("Lines[%d].Label = %v, expected %v", i, act, exp)
+		}
+	}
+	if act, exp := d2.Lines[0].Color, "blue"; act != exp {
+		t.Fatalf("Lines[0].Color = %v, expected %v", act, exp)
+	}
+}
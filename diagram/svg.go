@@ -0,0 +1,106 @@
+package diagram
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgCanvas draws diagram elements as SVG elements
+type svgCanvas struct {
+	width, height int
+	buf           bytes.Buffer
+	numClips      int
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill color.NRGBA) {
+	fmt.Fprintf(&c.buf, `<rect x="%s" y="%s" width="%s" height="%s" %s/>`+"\n",
+		svgNum(x), svgNum(y), svgNum(w), svgNum(h), svgPaint("fill", fill))
+}
+
+func (c *svgCanvas) polyline(xs, ys []float64, stroke color.NRGBA, width float64, dash []float64) {
+	if len(xs) == 0 {
+		return
+	}
+	points := make([]string, len(xs))
+	for i := range xs {
+		points[i] = svgNum(xs[i]) + "," + svgNum(ys[i])
+	}
+	fmt.Fprintf(&c.buf, `<polyline points="%s" fill="none" %s stroke-width="%s" stroke-linejoin="round"`,
+		strings.Join(points, " "), svgPaint("stroke", stroke), svgNum(width))
+	if len(dash) > 0 {
+		values := make([]string, len(dash))
+		for i, v := range dash {
+			values[i] = svgNum(v)
+		}
+		fmt.Fprintf(&c.buf, ` stroke-dasharray="%s"`, strings.Join(values, ","))
+	}
+	c.buf.WriteString("/>\n")
+}
+
+func (c *svgCanvas) circle(x, y, r float64, fill color.NRGBA) {
+	fmt.Fprintf(&c.buf, `<circle cx="%s" cy="%s" r="%s" %s/>`+"\n",
+		svgNum(x), svgNum(y), svgNum(r), svgPaint("fill", fill))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, size float64, anchor textAnchor, vertical bool, fill color.NRGBA) {
+	anchors := [...]string{"start", "middle", "end"}
+	fmt.Fprintf(&c.buf, `<text x="%s" y="%s" font-size="%s" text-anchor="%s" dominant-baseline="central" %s`,
+		svgNum(x), svgNum(y), svgNum(size), anchors[anchor], svgPaint("fill", fill))
+	if vertical {
+		fmt.Fprintf(&c.buf, ` transform="rotate(-90 %s %s)"`, svgNum(x), svgNum(y))
+	}
+	c.buf.WriteString(">")
+	xml.EscapeText(&c.buf, []byte(s))
+	c.buf.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) clip(x, y, w, h float64) {
+	c.numClips++
+	fmt.Fprintf(&c.buf, `<clipPath id="clip%d"><rect x="%s" y="%s" width="%s" height="%s"/></clipPath>`+"\n",
+		c.numClips, svgNum(x), svgNum(y), svgNum(w), svgNum(h))
+	fmt.Fprintf(&c.buf, `<g clip-path="url(#clip%d)">`+"\n", c.numClips)
+}
+
+func (c *svgCanvas) unclip() {
+	c.buf.WriteString("</g>\n")
+}
+
+// write writes the SVG document
+func (c *svgCanvas) write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		c.width, c.height, c.width, c.height); err != nil {
+		return fmt.Errorf("error writing SVG: %w", err)
+	}
+	if _, err := c.buf.WriteTo(w); err != nil {
+		return fmt.Errorf("error writing SVG: %w", err)
+	}
+	if _, err := io.WriteString(w, "</svg>\n"); err != nil {
+		return fmt.Errorf("error writing SVG: %w", err)
+	}
+	return nil
+}
+
+// svgNum formats a coordinate with at most two decimals
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// svgPaint returns the fill or stroke attribute for the color, with an
+// opacity attribute if the color is translucent
+func svgPaint(attr string, c color.NRGBA) string {
+	s := fmt.Sprintf(`%s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A < 255 {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, svgNum(float64(c.A)/255))
+	}
+	return s
+}
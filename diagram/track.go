@@ -0,0 +1,112 @@
+package diagram
+
+import (
+	"acdc/lin"
+	"fmt"
+)
+
+// gapPenalty is the reduction in link weight for each operating point
+// skipped when connecting modes across a gap
+const gapPenalty = 0.1
+
+// trackModesGlobal builds connected sets of modes by considering the links
+// between modes at all operating points at once. Each mode may be linked to
+// a mode at any of the following LookAhead+1 operating points, which allows
+// lines to bridge operating points where a mode is missing or out of range.
+//
+// Selecting the links is a minimum cost path cover of the graph of modes,
+// which is solved as an assignment of each mode to its successor. Each mode
+// may also be assigned to its own end column at a cost of 1, which leaves it
+// without a successor, so a link is only made if its weight (correlation
+// with frequency, damping, and gap penalties) is positive. Only the modes at
+// the operating points within look ahead distance are candidates, so the
+// assignment is sparse and grows linearly with the number of operating points.
+func trackModesGlobal(OPs []lin.LinOP, opts Options) ([]*ModeSet, error) {
+
+	freqRangeHz := [2]float64{opts.MinFreq, opts.MaxFreq}
+
+	// Collect modes to track from all operating points
+	modes := []*lin.Mode{}
+	opModes := make([][]int, len(OPs))
+	for i := range OPs {
+		for j := range OPs[i].Modes {
+			if m := &OPs[i].Modes[j]; m.Filter(freqRangeHz, opts.FilterStruct) {
+				opModes[i] = append(opModes[i], len(modes))
+				modes = append(modes, m)
+			}
+		}
+	}
+	if len(modes) == 0 {
+		return []*ModeSet{}, nil
+	}
+
+	// Loop through modes and the modes at the following operating points
+	// within look ahead distance
+	lookAhead := max(opts.LookAhead, 0)
+	rows := make([][]AssignmentCandidate, len(modes))
+	for op := range opModes {
+		for _, u := range opModes[op] {
+			mu := modes[u]
+			for gap := 0; gap <= lookAhead && op+gap+1 < len(opModes); gap++ {
+				for _, v := range opModes[op+gap+1] {
+					mv := modes[v]
+
+					// Calculate correlation between mode shapes, skip if below minimum
+					c, err := correlation(opts.Metric, mu, mv)
+					if err != nil {
+						return nil, err
+					}
+					if c < opts.MinCorrelation {
+						continue
+					}
+
+					// Calculate link weight and add candidate if better than leaving unlinked
+					w := c*trackingPenalty(mu, mv, freqRangeHz, opts) - gapPenalty*float64(gap)
+					if w > 0 {
+						rows[u] = append(rows[u], AssignmentCandidate{Col: v, Cost: 1 - min(w, 1)})
+					}
+				}
+			}
+
+			// Add end column for leaving mode without a successor
+			rows[u] = append(rows[u], AssignmentCandidate{Col: len(modes) + u, Cost: 1})
+		}
+	}
+
+	// Find links that minimize the total cost
+	pairs, err := SparseLinearAssignment(rows, 2*len(modes))
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect successor and predecessor of each mode from links
+	next := make([]int, len(modes))
+	hasPrev := make([]bool, len(modes))
+	for i := range next {
+		next[i] = -1
+	}
+	for _, pair := range pairs {
+		if pair[1] < len(modes) {
+			next[pair[0]] = pair[1]
+			hasPrev[pair[1]] = true
+		}
+	}
+
+	// Create mode set for each chain of links starting at a mode without a predecessor
+	modeSets := []*ModeSet{}
+	for u := range modes {
+		if hasPrev[u] {
+			continue
+		}
+		ms := &ModeSet{
+			ID:    len(modeSets),
+			Label: fmt.Sprintf("%d", len(modeSets)),
+		}
+		for v := u; v >= 0; v = next[v] {
+			ms.Modes = append(ms.Modes, modes[v])
+		}
+		modeSets = append(modeSets, ms)
+	}
+
+	return finalizeModeSets(modeSets), nil
+}
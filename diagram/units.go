@@ -0,0 +1,192 @@
+package diagram
+
+import (
+	"fmt"
+	"math"
+)
+
+// Frequency units of diagram series. PerRev normalizes the frequency by the
+// rotor frequency at the operating point.
+const (
+	FreqHz      = "Hz"
+	FreqRadPerS = "rad/s"
+	FreqPerRev  = "PerRev"
+)
+
+// Damping units of diagram series. LogDec is the logarithmic decrement.
+const (
+	DampRatio   = "Ratio"
+	DampPercent = "Percent"
+	DampLogDec  = "LogDec"
+)
+
+// rpmToHz converts rotor speed in RPM to rotor frequency in Hz
+const rpmToHz = 1.0 / 60.0
+
+// Units selects the units of the frequency and damping values in diagram
+// series. Empty fields use Hz and damping ratio.
+type Units struct {
+	Frequency string `json:"Frequency"` // Hz, rad/s, or PerRev
+	Damping   string `json:"Damping"`   // Ratio, Percent, or LogDec
+	Damped    bool   `json:"Damped"`    // Use damped instead of natural frequency
+}
+
+// NewUnits returns the units the diagram values are stored in
+func NewUnits() Units {
+	return Units{Frequency: FreqHz, Damping: DampRatio}
+}
+
+// Validate returns an error if the frequency or damping unit is unknown
+func (u Units) Validate() error {
+	switch u.Frequency {
+	case FreqHz, FreqRadPerS, FreqPerRev, "":
+	default:
+		return fmt.Errorf("unknown frequency unit '%s'", u.Frequency)
+	}
+	switch u.Damping {
+	case DampRatio, DampPercent, DampLogDec, "":
+	default:
+		return fmt.Errorf("unknown damping unit '%s'", u.Damping)
+	}
+	return nil
+}
+
+// FrequencyLabel returns the axis label for frequency values
+func (u Units) FrequencyLabel() string {
+	name := "Natural Frequency"
+	if u.Damped {
+		name = "Damped Frequency"
+	}
+	switch u.Frequency {
+	case FreqRadPerS:
+		return name + " (rad/s)"
+	case FreqPerRev:
+		return name + " (per rev)"
+	default:
+		return name + " (Hz)"
+	}
+}
+
+// DampingLabel returns the axis label for damping values
+func (u Units) DampingLabel() string {
+	switch u.Damping {
+	case DampPercent:
+		return "Damping Ratio (%)"
+	case DampLogDec:
+		return "Logarithmic Decrement (-)"
+	default:
+		return "Damping Ratio (-)"
+	}
+}
+
+// ConvertFrequency converts a frequency in Hz at the rotor speed in RPM.
+// Per-rev frequencies are undefined (NaN) when the rotor isn't spinning.
+func (u Units) ConvertFrequency(freqHz, rotSpeed float64) float64 {
+	switch u.Frequency {
+	case FreqRadPerS:
+		return 2 * math.Pi * freqHz
+	case FreqPerRev:
+		if rotSpeed == 0 {
+			return math.NaN()
+		}
+		return freqHz / (rotSpeed * rpmToHz)
+	default:
+		return freqHz
+	}
+}
+
+// ConvertDamping converts a damping ratio. The logarithmic decrement is
+// undefined (NaN) for damping ratios with a magnitude of one or more.
+func (u Units) ConvertDamping(ratio float64) float64 {
+	switch u.Damping {
+	case DampPercent:
+		return 100 * ratio
+	case DampLogDec:
+		if math.Abs(ratio) >= 1 {
+			return math.NaN()
+		}
+		return 2 * math.Pi * ratio / math.Sqrt(1-ratio*ratio)
+	default:
+		return ratio
+	}
+}
+
+// Series contains the diagram lines with frequency and damping values
+// converted to the selected units, for plotting and exporting.
+type Series struct {
+	Units          Units        `json:"Units"`
+	HasWind        bool         `json:"HasWind"`
+	FrequencyLabel string       `json:"FrequencyLabel"`
+	DampingLabel   string       `json:"DampingLabel"`
+	RotorFreq      []float64    `json:"RotorFreq"` // Rotor frequency (1P) at each operating point
+	Lines          []SeriesLine `json:"Lines"`
+}
+
+type SeriesLine struct {
+	ID     int           `json:"ID"`
+	Label  string        `json:"Label"`
+	Hidden bool          `json:"Hidden"`
+	Points []SeriesPoint `json:"Points"`
+}
+
+type SeriesPoint struct {
+	OP        int     `json:"OpPtID"`
+	Mode      int     `json:"ModeID"`
+	RotSpeed  float32 `json:"RotSpeed"`
+	WindSpeed float32 `json:"WindSpeed"`
+	Frequency float64 `json:"Frequency"`
+	Damping   float64 `json:"Damping"`
+}
+
+// Series returns the diagram lines with values converted to the units.
+// Points with an undefined value in the units are left out of the lines.
+func (d *Diagram) Series(u Units) (*Series, error) {
+
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Series{
+		Units:          u,
+		HasWind:        d.HasWind,
+		FrequencyLabel: u.FrequencyLabel(),
+		DampingLabel:   u.DampingLabel(),
+		RotorFreq:      make([]float64, len(d.RotSpeeds)),
+		Lines:          make([]SeriesLine, len(d.Lines)),
+	}
+
+	// Rotor frequency is one per rev, even if the rotor isn't spinning
+	for i, rotSpeed := range d.RotSpeeds {
+		if u.Frequency == FreqPerRev {
+			s.RotorFreq[i] = 1
+		} else {
+			s.RotorFreq[i] = u.ConvertFrequency(float64(rotSpeed)*rpmToHz, float64(rotSpeed))
+		}
+	}
+
+	// Convert point values
+	for i, line := range d.Lines {
+		sl := SeriesLine{ID: line.ID, Label: line.Label, Hidden: line.Hidden, Points: []SeriesPoint{}}
+		for _, p := range line.Points {
+			freqHz := p.NaturalFreqHz
+			if u.Damped {
+				freqHz = p.DampedFreqHz
+			}
+			sp := SeriesPoint{
+				OP:        p.OP,
+				Mode:      p.Mode,
+				RotSpeed:  p.RotSpeed,
+				WindSpeed: p.WindSpeed,
+				Frequency: u.ConvertFrequency(float64(freqHz), float64(p.RotSpeed)),
+				Damping:   u.ConvertDamping(float64(p.DampingRatio)),
+			}
+			if math.IsNaN(sp.Frequency) || math.IsNaN(sp.Damping) {
+				continue
+			}
+			sl.Points = append(sl.Points, sp)
+		}
+		s.Lines[i] = sl
+	}
+
+	return s, nil
+}
@@ -0,0 +1,50 @@
+package diagram_test
+
+import (
+	"acdc/diagram"
+	"math"
+	"testing"
+)
+
+func TestDiagramSeries(t *testing.T) {
+
+	d := newTestDiagram([]float32{0, 6, 12}, [][]float32{{0.3, 0.4, 0.5}})
+
+	testCases := []struct {
+		units     diagram.Units
+		freqs     []float64
+		damping   float64 // Damping of first point
+		rotorFreq float64 // Rotor frequency at last OP
+	}{
+		{diagram.NewUnits(), []float64{0.3, 0.4, 0.5}, 0.01, 0.2},
+		{diagram.Units{Frequency: diagram.FreqRadPerS, Damping: diagram.DampPercent}, []float64{0.6 * math.Pi, 0.8 * math.Pi, math.Pi}, 1, 0.4 * math.Pi},
+		{diagram.Units{Frequency: diagram.FreqPerRev, Damping: diagram.DampLogDec}, []float64{4, 2.5}, 2 * math.Pi * 0.01 / math.Sqrt(1-0.01*0.01), 1},
+	}
+
+	for _, tc := range testCases {
+		s, err := d.Series(tc.units)
+		if err != nil {
+			t.Fatal(err)
+		}
+		points := s.Lines[0].Points
+		if act, exp := len(points), len(tc.freqs); act != exp {
+			t.Fatalf("%v: len(Points) = %v, expected %v", tc.units, act, exp)
+		}
+		for i, exp := range tc.freqs {
+			if act := points[i].Frequency; math.Abs(act-exp) > 1e-6 {
+				t.Fatalf("%v: Points[%d].Frequency = %v, expected %v", tc.units, i, act, exp)
+			}
+		}
+		if act, exp := points[0].Damping, tc.damping; math.Abs(act-exp) > 1e-6 {
+			t.Fatalf("%v: Points[0].Damping = %v, expected %v", tc.units, act, exp)
+		}
+		if act, exp := s.RotorFreq[2], tc.rotorFreq; math.Abs(act-exp) > 1e-6 {
+			t.Fatalf("%v: RotorFreq[2] = %v, expected %v", tc.units, act, exp)
+		}
+	}
+
+	// Unknown units should return an error
+	if _, err := d.Series(diagram.Units{Frequency: "RPM"}); err == nil {
+		t.Fatal("expected error for unknown frequency unit")
+	}
+}
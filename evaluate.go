@@ -51,13 +51,13 @@ type EvalStatus struct {
 
 var EvalCancel context.CancelCauseFunc = func(_ error) {}
 
-func (eval *Evaluate) Case(appCtx context.Context, model *Model, c *Case, projectRootPath string) ([]EvalStatus, error) {
+// Case evaluates all operating points of the case in caseDir
+func (eval *Evaluate) Case(appCtx context.Context, model *Model, c *Case, caseDir string) ([]EvalStatus, error) {
 
 	// Call existing cancel func
 	EvalCancel(fmt.Errorf("new evaluation started"))
 
-	// Create path to case directory
-	caseDir := filepath.Join(projectRootPath, fmt.Sprintf("Case%02d", c.ID))
+	// Create case directory
 	if err := os.MkdirAll(caseDir, 0777); err != nil {
 		return nil, fmt.Errorf("error creating directory '%s': %w", caseDir, err)
 	}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"acdc/diagram"
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table is a named table of cells exported to a CSV file or a sheet of an
+// XLSX workbook. Cells may be strings, numbers, or nil for empty cells.
+type Table struct {
+	Name string
+	Rows [][]any
+}
+
+// ExportTables returns the metadata, diagram, and mode tables for the
+// loaded results. The diagram table is omitted if no diagram is loaded.
+func (p *Project) ExportTables(units diagram.Units) ([]Table, error) {
+
+	if p.Results == nil {
+		return nil, fmt.Errorf("load results before exporting tables")
+	}
+
+	tables := []Table{p.metadataTable(units)}
+
+	// Add diagram table
+	if p.Diagram != nil {
+		t, err := diagramTable(p.Diagram, units)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+
+	return append(tables, modesTable(p.Results)), nil
+}
+
+// metadataTable returns a table of properties describing the project,
+// results, case, and diagram
+func (p *Project) metadataTable(units diagram.Units) Table {
+
+	t := Table{Name: "Metadata", Rows: [][]any{{"Property", "Value"}}}
+	add := func(name string, value any) { t.Rows = append(t.Rows, []any{name, value}) }
+
+	add("ACDC Version", version)
+	add("Export Date", time.Now().Format(time.RFC850))
+	add("Project", p.Info.Path)
+	add("Results Directory", p.Results.LinDir)
+	add("Operating Points", len(p.Results.OPs))
+
+	// Add case and run the results belong to, if found
+	if run := p.resultsRun(); run != nil {
+		add("Run", run.Label)
+		add("Run Date", run.Date)
+		addCaseMetadata(add, &run.Case)
+	} else if c := p.resultsCase(); c != nil {
+		addCaseMetadata(add, c)
+	}
+
+	// Add diagram settings
+	if p.Diagram != nil {
+		opts := p.Diagram.Options
+		add("Diagram Lines", len(p.Diagram.Lines))
+		add("Diagram Frequency Range (Hz)", fmt.Sprintf("%g - %g", opts.MinFreq, opts.MaxFreq))
+		add("Diagram Tracking", opts.Tracking)
+		add("Diagram Metric", opts.Metric)
+		add("Frequency Units", units.FrequencyLabel())
+		add("Damping Units", units.DampingLabel())
+	}
+
+	return t
+}
+
+// addCaseMetadata adds the case properties to the metadata table
+func addCaseMetadata(add func(string, any), c *Case) {
+	add("Case ID", c.ID)
+	add("Case Name", c.Name)
+	add("Include Aero", strconv.FormatBool(c.IncludeAero))
+	add("Use Controller", strconv.FormatBool(c.UseController))
+	add("Rated Wind Speed (m/s)", c.RatedWindSpeed)
+	add("Rated Rotor Speed (RPM)", c.RatedRotorSpeed)
+}
+
+// resultsRun returns the run whose directory contains the loaded results
+func (p *Project) resultsRun() *Run {
+	for i := range p.Runs {
+		if filepath.Clean(p.RunDir(&p.Runs[i])) == filepath.Clean(p.Results.LinDir) {
+			return &p.Runs[i]
+		}
+	}
+	return nil
+}
+
+// resultsCase returns the case whose directory contains the loaded results
+func (p *Project) resultsCase() *Case {
+	if p.Analysis == nil {
+		return nil
+	}
+	for i := range p.Analysis.Cases {
+		if filepath.Clean(p.CaseDir(p.Analysis.Cases[i].ID)) == filepath.Clean(p.Results.LinDir) {
+			return &p.Analysis.Cases[i]
+		}
+	}
+	return nil
+}
+
+// diagramTable returns a table with a row for each operating point and
+// frequency and damping columns for each diagram line, in the given units
+func diagramTable(diag *diagram.Diagram, units diagram.Units) (Table, error) {
+
+	// Convert line values to units
+	s, err := diag.Series(units)
+	if err != nil {
+		return Table{}, err
+	}
+
+	// Create header and rows with operating point speeds
+	header := []any{"Operating Point", "Rotor Speed (RPM)", "Wind Speed (m/s)"}
+	rows := make([][]any, len(diag.RotSpeeds))
+	for i := range rows {
+		rows[i] = []any{i + 1, diag.RotSpeeds[i], diag.WindSpeeds[i]}
+	}
+
+	// Add frequency and damping columns for each line
+	for _, line := range s.Lines {
+		header = append(header,
+			line.Label+" "+s.FrequencyLabel,
+			line.Label+" "+s.DampingLabel)
+		freqs := make([]any, len(rows))
+		damps := make([]any, len(rows))
+		for _, p := range line.Points {
+			if p.OP >= 0 && p.OP < len(rows) {
+				freqs[p.OP], damps[p.OP] = p.Frequency, p.Damping
+			}
+		}
+		for i := range rows {
+			rows[i] = append(rows[i], freqs[i], damps[i])
+		}
+	}
+
+	return Table{Name: "Diagram", Rows: append([][]any{header}, rows...)}, nil
+}
+
+// modesTable returns a table with a row for each mode at each operating point
+func modesTable(res *Results) Table {
+	t := Table{Name: "Modes", Rows: [][]any{{
+		"Operating Point", "Rotor Speed (RPM)", "Wind Speed (m/s)", "Mode",
+		"Natural Frequency (Hz)", "Damped Frequency (Hz)", "Damping Ratio (-)",
+	}}}
+	for _, op := range res.OPs {
+		for _, m := range op.Modes {
+			t.Rows = append(t.Rows, []any{
+				op.ID + 1, op.RotSpeed, op.WindSpeed, m.ID + 1,
+				m.NaturalFreqHz, m.DampedFreqHz, m.DampingRatio,
+			})
+		}
+	}
+	return t
+}
+
+//------------------------------------------------------------------------------
+// CSV
+//------------------------------------------------------------------------------
+
+// WriteTablesCSV writes each table to a CSV file in the directory named
+// with the lowercase table name
+func WriteTablesCSV(dir string, tables []Table) error {
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return fmt.Errorf("error creating export directory '%s': %w", dir, err)
+	}
+
+	for _, t := range tables {
+		path := filepath.Join(dir, strings.ToLower(t.Name)+".csv")
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating CSV file '%s': %w", path, err)
+		}
+		if err := writeCSV(f, t); err != nil {
+			f.Close()
+			return fmt.Errorf("error writing CSV file '%s': %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("error writing CSV file '%s': %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// writeCSV writes the table rows as CSV records
+func writeCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	for _, row := range t.Rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = formatCell(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatCell returns the text of the cell value, empty for nil
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+//------------------------------------------------------------------------------
+// XLSX
+//------------------------------------------------------------------------------
+
+// WriteTablesXLSX writes the tables as sheets of an XLSX workbook at the path
+func WriteTablesXLSX(path string, tables []Table) error {
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating workbook '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	if err := writeXLSX(f, tables); err != nil {
+		return fmt.Errorf("error writing workbook '%s': %w", path, err)
+	}
+
+	return f.Close()
+}
+
+// writeXLSX writes a minimal Office Open XML workbook with a sheet for each
+// table. Strings are stored inline so no shared string table is needed.
+func writeXLSX(w io.Writer, tables []Table) error {
+
+	zw := zip.NewWriter(w)
+
+	// Build workbook parts listing the sheets
+	contentTypes := &strings.Builder{}
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook := &strings.Builder{}
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels := &strings.Builder{}
+	workbookRels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, t := range tables {
+		n := i + 1
+		fmt.Fprintf(contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheetName(t.Name)), n, n)
+		fmt.Fprintf(workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, t := range tables {
+		parts = append(parts, struct{ name, content string }{
+			fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheet(t),
+		})
+	}
+
+	// Write parts to archive
+	for _, part := range parts {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// xlsxStyles defines the default cell style and a bold style for header rows
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// xlsxSheet returns the worksheet XML of the table with the first row in bold
+func xlsxSheet(t Table) string {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<sheetData>`)
+	for i, row := range t.Rows {
+		fmt.Fprintf(b, `<row r="%d">`, i+1)
+		style := ""
+		if i == 0 {
+			style = ` s="1"`
+		}
+		for j, v := range row {
+			ref := columnName(j) + strconv.Itoa(i+1)
+			switch v.(type) {
+			case nil:
+				continue
+			case int, float32, float64:
+				fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, style, formatCell(v))
+			default:
+				fmt.Fprintf(b, `<c r="%s"%s t="inlineStr"><is><t>%s</t></is></c>`, ref, style, xmlEscape(formatCell(v)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnName returns the spreadsheet column name (A, B, ..., Z, AA, ...) of the index
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// sheetName removes characters which aren't allowed in sheet names and
+// limits the name to 31 characters
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 31 {
+		name = name[:31]
+	}
+	return name
+}
+
+// xmlEscape escapes the text for use in XML content and attributes
+func xmlEscape(s string) string {
+	b := &strings.Builder{}
+	xml.EscapeText(b, []byte(s))
+	return b.String()
+}
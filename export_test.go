@@ -0,0 +1,129 @@
+package main
+
+import (
+	"acdc/diagram"
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExportTables(t *testing.T) {
+
+	// Create project with a run, results, and diagram
+	p := NewProject()
+	p.Info.Path = filepath.Join(t.TempDir(), "project.json")
+	p.Analysis = NewAnalysis()
+	if _, err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	run, err := p.AddRun(&p.Analysis.Cases[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Results = &Results{
+		LinDir: p.RunDir(run),
+		OPs: []OperatingPoint{
+			{ID: 0, RotSpeed: 6, Modes: []Mode{{ID: 0, NaturalFreqHz: 0.3, DampedFreqHz: 0.29, DampingRatio: 0.01}}},
+			{ID: 1, RotSpeed: 12, Modes: []Mode{{ID: 0, OP: 1, NaturalFreqHz: 0.32, DampedFreqHz: 0.31, DampingRatio: 0.02}}},
+		},
+	}
+	p.Diagram = &diagram.Diagram{
+		RotSpeeds:  []float32{6, 12},
+		WindSpeeds: []float32{0, 0},
+		Lines: []diagram.Line{{ID: 0, Label: "Tower", Points: []diagram.Point{
+			{OP: 1, RotSpeed: 12, NaturalFreqHz: 0.32, DampedFreqHz: 0.31, DampingRatio: 0.02},
+		}}},
+		Options: diagram.NewOptions(),
+	}
+
+	tables, err := p.ExportTables(diagram.Units{Frequency: diagram.FreqPerRev, Damping: diagram.DampPercent})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Metadata should include run and case
+	meta := map[string]string{}
+	for _, row := range tables[0].Rows {
+		meta[formatCell(row[0])] = formatCell(row[1])
+	}
+	if act, exp := meta["Run"], run.Label; act != exp {
+		t.Fatalf("metadata Run = %v, expected %v", act, exp)
+	}
+	if act, exp := meta["Case Name"], p.Analysis.Cases[0].Name; act != exp {
+		t.Fatalf("metadata Case Name = %v, expected %v", act, exp)
+	}
+
+	// Write tables to CSV and check diagram values are in units
+	dir := filepath.Join(t.TempDir(), "csv")
+	if err := WriteTablesCSV(dir, tables); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dir, "diagram.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expRecords := [][]string{
+		{"Operating Point", "Rotor Speed (RPM)", "Wind Speed (m/s)", "Tower Natural Frequency (per rev)", "Tower Damping Ratio (%)"},
+		{"1", "6", "0", "", ""},
+		{"2", "12", "0", "1.5999999642372131", "1.9999999552965164"},
+	}
+	for i := range expRecords {
+		if !slices.Equal(records[i], expRecords[i]) {
+			t.Fatalf("diagram.csv row %d = %v, expected %v", i, records[i], expRecords[i])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "modes.csv")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write tables to workbook and check that all parts are valid XML
+	path := filepath.Join(t.TempDir(), "tables.xlsx")
+	if err := WriteTablesXLSX(path, tables); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	parts := map[string]string{}
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := xml.NewDecoder(strings.NewReader(string(bs)))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: invalid XML: %v", zf.Name, err)
+			}
+		}
+		parts[zf.Name] = string(bs)
+	}
+	for _, name := range []string{"Metadata", "Diagram", "Modes"} {
+		if !strings.Contains(parts["xl/workbook.xml"], `name="`+name+`"`) {
+			t.Fatalf("workbook doesn't contain sheet %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet3.xml"], `<c r="E3"><v>0.32</v></c>`) {
+		t.Fatal("modes sheet doesn't contain frequency of second operating point")
+	}
+}
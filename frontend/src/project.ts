@@ -1,14 +1,14 @@
 import { defineStore } from 'pinia'
-import { ref, reactive } from 'vue'
+import { ref, reactive, watch } from 'vue'
 import { LoadConfig, SaveConfig } from "../wailsjs/go/main/App"
 import { OpenProjectDialog, SaveProjectDialog, OpenProject } from '../wailsjs/go/main/App'
 import { FetchModel, UpdateModel, ImportModelDialog } from "../wailsjs/go/main/App"
 import { FetchAnalysis, UpdateAnalysis, AddAnalysisCase, DuplicateAnalysisCase, RemoveAnalysisCase, ImportAnalysisCaseCurve } from "../wailsjs/go/main/App"
 import { FetchEvaluate, UpdateEvaluate, SelectExec, EvaluateCase, CancelEvaluate } from "../wailsjs/go/main/App"
-import { FetchResults, SelectCaseLinDir, SelectCustomLinDir, ProcessLinDir } from "../wailsjs/go/main/App"
-import { GenerateDiagram, UpdateDiagram } from "../wailsjs/go/main/App"
+import { FetchResults, SelectCaseLinDir, SelectCustomLinDir, ProcessLinDir, CancelProcess } from "../wailsjs/go/main/App"
+import { GenerateDiagram, UpdateDiagram, DiagramSeries } from "../wailsjs/go/main/App"
 import { GetModeViz } from "../wailsjs/go/main/App"
-import { main, diagram as diag, viz } from "../wailsjs/go/models"
+import { main, diagram as diag, lin, viz } from "../wailsjs/go/models"
 import { EventsOn } from "../wailsjs/runtime/runtime"
 import { LogError } from '../wailsjs/runtime/runtime'
 
@@ -44,10 +44,22 @@ export const useProjectStore = defineStore('project', () => {
 
     const currentCaseID = ref<number>(1)
     const evalStatus = reactive<Array<main.EvalStatus>>(new Array)
+    const processStatus = ref<main.ProcessStatus | null>(null)
     const modeViz = reactive<Array<viz.ModeData>>(new Array)
     const currentVizID = ref<number>(-1)
-    const diagramOptions = ref<diag.Options>({ MinFreq: 0, MaxFreq: 10, Cluster: false, FilterStruct: false })
+    const diagramOptions = ref<diag.Options>({
+        MinFreq: 0, MaxFreq: 10, Cluster: false, FilterStruct: false,
+        Metric: "MAC", FreqWeight: 1, DampWeight: 0, MinCorrelation: 0,
+        Tracking: "Sequential", LookAhead: 2, ConfidenceMargin: 0.1, Seed: 1,
+    })
+    const diagramUnits = ref<diag.Units>({ Frequency: "Hz", Damping: "Ratio", Damped: false })
+    const diagramSeries = ref<diag.Series | null>(null)
     const linDir = ref<string>("")
+    const discoverOptions = ref<lin.DiscoverOptions>({
+        Recursive: false,
+        Patterns: ["*.lin"],
+        GroupBy: "Name",
+    })
 
     function $reset() {
         info.value = null
@@ -313,11 +325,17 @@ export const useProjectStore = defineStore('project', () => {
     // Results
     //--------------------------------------------------------------------------
 
+    // Set results and the discovery options used to process them
+    function setResults(res: main.Results) {
+        results.value = res
+        if (res?.Discover != null) discoverOptions.value = res.Discover
+    }
+
     function selectCaseLinDir(caseID: number) {
         SelectCaseLinDir(caseID).then(res => {
             linDir.value = res.Dir
             clearModeViz()
-            if (res.Results != null) results.value = res.Results
+            if (res.Results != null) setResults(res.Results)
             if (res.Diagram != null) diagram.value = res.Diagram
         }).catch(err => {
             LogError(err)
@@ -330,7 +348,7 @@ export const useProjectStore = defineStore('project', () => {
         SelectCustomLinDir().then(res => {
             linDir.value = res.Dir
             clearModeViz()
-            if (res.Results != null) results.value = res.Results
+            if (res.Results != null) setResults(res.Results)
             if (res.Diagram != null) diagram.value = res.Diagram
         }).catch(err => {
             LogError(err)
@@ -342,7 +360,7 @@ export const useProjectStore = defineStore('project', () => {
     function fetchResults() {
         if (results.value != null) return
         FetchResults().then(result => {
-            results.value = result
+            setResults(result)
             console.log(result)
         }).catch(err => {
             LogError(err)
@@ -354,9 +372,10 @@ export const useProjectStore = defineStore('project', () => {
     function processLinDir() {
         if (linDir.value == "") return
         status.results = LOADING
-        ProcessLinDir(linDir.value).then(result => {
+        processStatus.value = null
+        ProcessLinDir(linDir.value, discoverOptions.value).then(result => {
             diagram.value = null
-            results.value = result
+            setResults(result)
             const maxRotSpeed = Math.max(...results.value.OPs.map(op => op.RotSpeed))
             diagramOptions.value.MinFreq = 0
             diagramOptions.value.MaxFreq = parseFloat((maxRotSpeed / 60.0 * 15.0).toFixed(2))
@@ -370,6 +389,19 @@ export const useProjectStore = defineStore('project', () => {
         })
     }
 
+    function cancelProcess() {
+        CancelProcess().catch(err => {
+            LogError(err)
+            errMsg.value = err
+            console.log(err)
+        })
+    }
+
+    // Setup listener for results processing status updates
+    EventsOn("processStatus", (status: main.ProcessStatus) => {
+        processStatus.value = status
+    })
+
     //--------------------------------------------------------------------------
     // Diagram
     //--------------------------------------------------------------------------
@@ -388,6 +420,22 @@ export const useProjectStore = defineStore('project', () => {
         })
     }
 
+    // Convert diagram values to the selected units when either changes
+    function fetchDiagramSeries() {
+        if (diagram.value == null) {
+            diagramSeries.value = null
+            return
+        }
+        DiagramSeries(diagram.value, diagramUnits.value).then(result => {
+            diagramSeries.value = result
+        }).catch(err => {
+            LogError(err)
+            errMsg.value = err
+            console.log(err)
+        })
+    }
+    watch([diagram, diagramUnits], fetchDiagramSeries, { deep: true })
+
     function updateDiagram() {
         if (diagram.value == null) return
         UpdateDiagram(diagram.value).catch(err => {
@@ -482,9 +530,14 @@ export const useProjectStore = defineStore('project', () => {
         results,
         fetchResults,
         processLinDir,
+        discoverOptions,
+        processStatus,
+        cancelProcess,
         // Diagram
         diagram,
         diagramOptions,
+        diagramUnits,
+        diagramSeries,
         generateDiagram,
         updateDiagram,
         // Visualization
@@ -3,16 +3,33 @@
 import {main} from '../models';
 import {diagram} from '../models';
 import {viz} from '../models';
+import {lin} from '../models';
 
 export function AddAnalysisCase():Promise<main.Analysis>;
 
+export function ApplyStyleTemplateDialog():Promise<diagram.Diagram>;
+
 export function CancelEvaluate():Promise<void>;
 
+export function CancelProcess():Promise<void>;
+
+export function CompareDiagrams(arg1:main.CompareSource,arg2:main.CompareSource,arg3:diagram.CompareOptions):Promise<diagram.Comparison>;
+
+export function DiagramSeries(arg1:diagram.Diagram,arg2:diagram.Units):Promise<diagram.Series>;
+
 export function DuplicateAnalysisCase(arg1:number):Promise<main.Analysis>;
 
 export function EvaluateCase(arg1:number):Promise<Array<main.EvalStatus>>;
 
-export function ExportDiagramDataJSON(arg1:diagram.Diagram):Promise<void>;
+export function ExportBundleDialog(arg1:main.BundleOptions):Promise<void>;
+
+export function ExportDiagramDataJSON(arg1:diagram.Diagram,arg2:diagram.Units):Promise<void>;
+
+export function ExportDiagramImageDialog(arg1:diagram.RenderOptions):Promise<void>;
+
+export function ExportModesCSVDialog():Promise<void>;
+
+export function ExportTablesDialog(arg1:string,arg2:diagram.Units):Promise<void>;
 
 export function FetchAnalysis():Promise<main.Analysis>;
 
@@ -22,6 +39,12 @@ export function FetchModel():Promise<main.Model>;
 
 export function FetchResults():Promise<main.Results>;
 
+export function FetchRunData(arg1:number,arg2:number):Promise<main.LinDirData>;
+
+export function FetchRuns(arg1:number):Promise<Array<main.Run>>;
+
+export function FetchUndoState():Promise<main.UndoState>;
+
 export function GenerateDiagram(arg1:diagram.Options):Promise<diagram.Diagram>;
 
 export function GetEvaluateLog(arg1:string):Promise<string>;
@@ -30,28 +53,48 @@ export function GetModeViz(arg1:number,arg2:number,arg3:number):Promise<viz.Mode
 
 export function ImportAnalysisCaseCurve(arg1:number):Promise<main.Analysis>;
 
+export function ImportBundleDialog():Promise<main.Info>;
+
 export function ImportModelDialog():Promise<main.Model>;
 
 export function LoadConfig():Promise<main.Config>;
 
+export function MergeDiagramLines(arg1:number,arg2:number):Promise<diagram.Diagram>;
+
+export function MoveDiagramPoint(arg1:number,arg2:number,arg3:number):Promise<diagram.Diagram>;
+
 export function OpenProject(arg1:string):Promise<main.Info>;
 
 export function OpenProjectDialog():Promise<main.Info>;
 
-export function ProcessLinDir(arg1:string):Promise<main.Results>;
+export function ProcessLinDir(arg1:string,arg2:lin.DiscoverOptions):Promise<main.Results>;
+
+export function Redo():Promise<main.UndoState>;
 
 export function RemoveAnalysisCase(arg1:number):Promise<main.Analysis>;
 
+export function RemoveRun(arg1:number,arg2:number):Promise<Array<main.Run>>;
+
 export function SaveConfig(arg1:main.Config):Promise<void>;
 
 export function SaveProjectDialog():Promise<main.Info>;
 
+export function SaveStyleTemplateDialog():Promise<void>;
+
 export function SelectCaseLinDir(arg1:number):Promise<main.LinDirData>;
 
 export function SelectCustomLinDir():Promise<main.LinDirData>;
 
 export function SelectExec():Promise<main.Evaluate>;
 
+export function SelectRunLinDir(arg1:number,arg2:number):Promise<main.LinDirData>;
+
+export function SplitDiagramLine(arg1:number,arg2:number):Promise<diagram.Diagram>;
+
+export function SwapDiagramLines(arg1:number,arg2:number,arg3:number):Promise<diagram.Diagram>;
+
+export function Undo():Promise<main.UndoState>;
+
 export function UpdateAnalysis(arg1:main.Analysis):Promise<main.Analysis>;
 
 export function UpdateDiagram(arg1:diagram.Diagram):Promise<void>;
@@ -59,3 +102,5 @@ export function UpdateDiagram(arg1:diagram.Diagram):Promise<void>;
 export function UpdateEvaluate(arg1:main.Evaluate):Promise<main.Evaluate>;
 
 export function UpdateModel(arg1:main.Model):Promise<main.Model>;
+
+export function UpdateRun(arg1:main.Run):Promise<Array<main.Run>>;
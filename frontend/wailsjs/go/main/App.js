@@ -6,10 +6,26 @@ export function AddAnalysisCase() {
   return window['go']['main']['App']['AddAnalysisCase']();
 }
 
+export function ApplyStyleTemplateDialog() {
+  return window['go']['main']['App']['ApplyStyleTemplateDialog']();
+}
+
 export function CancelEvaluate() {
   return window['go']['main']['App']['CancelEvaluate']();
 }
 
+export function CancelProcess() {
+  return window['go']['main']['App']['CancelProcess']();
+}
+
+export function CompareDiagrams(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CompareDiagrams'](arg1, arg2, arg3);
+}
+
+export function DiagramSeries(arg1, arg2) {
+  return window['go']['main']['App']['DiagramSeries'](arg1, arg2);
+}
+
 export function DuplicateAnalysisCase(arg1) {
   return window['go']['main']['App']['DuplicateAnalysisCase'](arg1);
 }
@@ -18,8 +34,24 @@ export function EvaluateCase(arg1) {
   return window['go']['main']['App']['EvaluateCase'](arg1);
 }
 
-export function ExportDiagramDataJSON(arg1) {
-  return window['go']['main']['App']['ExportDiagramDataJSON'](arg1);
+export function ExportBundleDialog(arg1) {
+  return window['go']['main']['App']['ExportBundleDialog'](arg1);
+}
+
+export function ExportDiagramDataJSON(arg1, arg2) {
+  return window['go']['main']['App']['ExportDiagramDataJSON'](arg1, arg2);
+}
+
+export function ExportDiagramImageDialog(arg1) {
+  return window['go']['main']['App']['ExportDiagramImageDialog'](arg1);
+}
+
+export function ExportModesCSVDialog() {
+  return window['go']['main']['App']['ExportModesCSVDialog']();
+}
+
+export function ExportTablesDialog(arg1, arg2) {
+  return window['go']['main']['App']['ExportTablesDialog'](arg1, arg2);
 }
 
 export function FetchAnalysis() {
@@ -38,6 +70,18 @@ export function FetchResults() {
   return window['go']['main']['App']['FetchResults']();
 }
 
+export function FetchRunData(arg1, arg2) {
+  return window['go']['main']['App']['FetchRunData'](arg1, arg2);
+}
+
+export function FetchRuns(arg1) {
+  return window['go']['main']['App']['FetchRuns'](arg1);
+}
+
+export function FetchUndoState() {
+  return window['go']['main']['App']['FetchUndoState']();
+}
+
 export function GenerateDiagram(arg1) {
   return window['go']['main']['App']['GenerateDiagram'](arg1);
 }
@@ -54,6 +98,10 @@ export function ImportAnalysisCaseCurve(arg1) {
   return window['go']['main']['App']['ImportAnalysisCaseCurve'](arg1);
 }
 
+export function ImportBundleDialog() {
+  return window['go']['main']['App']['ImportBundleDialog']();
+}
+
 export function ImportModelDialog() {
   return window['go']['main']['App']['ImportModelDialog']();
 }
@@ -62,6 +110,14 @@ export function LoadConfig() {
   return window['go']['main']['App']['LoadConfig']();
 }
 
+export function MergeDiagramLines(arg1, arg2) {
+  return window['go']['main']['App']['MergeDiagramLines'](arg1, arg2);
+}
+
+export function MoveDiagramPoint(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveDiagramPoint'](arg1, arg2, arg3);
+}
+
 export function OpenProject(arg1) {
   return window['go']['main']['App']['OpenProject'](arg1);
 }
@@ -70,14 +126,22 @@ export function OpenProjectDialog() {
   return window['go']['main']['App']['OpenProjectDialog']();
 }
 
-export function ProcessLinDir(arg1) {
-  return window['go']['main']['App']['ProcessLinDir'](arg1);
+export function ProcessLinDir(arg1, arg2) {
+  return window['go']['main']['App']['ProcessLinDir'](arg1, arg2);
+}
+
+export function Redo() {
+  return window['go']['main']['App']['Redo']();
 }
 
 export function RemoveAnalysisCase(arg1) {
   return window['go']['main']['App']['RemoveAnalysisCase'](arg1);
 }
 
+export function RemoveRun(arg1, arg2) {
+  return window['go']['main']['App']['RemoveRun'](arg1, arg2);
+}
+
 export function SaveConfig(arg1) {
   return window['go']['main']['App']['SaveConfig'](arg1);
 }
@@ -86,6 +150,10 @@ export function SaveProjectDialog() {
   return window['go']['main']['App']['SaveProjectDialog']();
 }
 
+export function SaveStyleTemplateDialog() {
+  return window['go']['main']['App']['SaveStyleTemplateDialog']();
+}
+
 export function SelectCaseLinDir(arg1) {
   return window['go']['main']['App']['SelectCaseLinDir'](arg1);
 }
@@ -98,6 +166,22 @@ export function SelectExec() {
   return window['go']['main']['App']['SelectExec']();
 }
 
+export function SelectRunLinDir(arg1, arg2) {
+  return window['go']['main']['App']['SelectRunLinDir'](arg1, arg2);
+}
+
+export function SplitDiagramLine(arg1, arg2) {
+  return window['go']['main']['App']['SplitDiagramLine'](arg1, arg2);
+}
+
+export function SwapDiagramLines(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SwapDiagramLines'](arg1, arg2, arg3);
+}
+
+export function Undo() {
+  return window['go']['main']['App']['Undo']();
+}
+
 export function UpdateAnalysis(arg1) {
   return window['go']['main']['App']['UpdateAnalysis'](arg1);
 }
@@ -113,3 +197,7 @@ export function UpdateEvaluate(arg1) {
 export function UpdateModel(arg1) {
   return window['go']['main']['App']['UpdateModel'](arg1);
 }
+
+export function UpdateRun(arg1) {
+  return window['go']['main']['App']['UpdateRun'](arg1);
+}
@@ -9,6 +9,10 @@ export namespace diagram {
 	    NaturalFreqHz: number;
 	    DampedFreqHz: number;
 	    DampingRatio: number;
+	    Correlation: number;
+	    Weight: number;
+	    RunnerUpMode: number;
+	    RunnerUpWeight: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Point(source);
@@ -24,6 +28,10 @@ export namespace diagram {
 	        this.NaturalFreqHz = source["NaturalFreqHz"];
 	        this.DampedFreqHz = source["DampedFreqHz"];
 	        this.DampingRatio = source["DampingRatio"];
+	        this.Correlation = source["Correlation"];
+	        this.Weight = source["Weight"];
+	        this.RunnerUpMode = source["RunnerUpMode"];
+	        this.RunnerUpWeight = source["RunnerUpWeight"];
 	    }
 	}
 	export class Line {
@@ -33,6 +41,8 @@ export namespace diagram {
 	    Dash: number[];
 	    Hidden: boolean;
 	    Points: Point[];
+	    LowConfidence: number[];
+	    Swaps: LineSwap[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Line(source);
@@ -46,6 +56,8 @@ export namespace diagram {
 	        this.Dash = source["Dash"];
 	        this.Hidden = source["Hidden"];
 	        this.Points = this.convertValues(source["Points"], Point);
+	        this.LowConfidence = source["LowConfidence"];
+	        this.Swaps = this.convertValues(source["Swaps"], LineSwap);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -67,10 +79,12 @@ export namespace diagram {
 		}
 	}
 	export class Diagram {
+	    SchemaVersion: number;
 	    HasWind: boolean;
 	    RotSpeeds: number[];
 	    WindSpeeds: number[];
 	    Lines: Line[];
+	    Options: Options;
 	
 	    static createFrom(source: any = {}) {
 	        return new Diagram(source);
@@ -78,10 +92,12 @@ export namespace diagram {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.SchemaVersion = source["SchemaVersion"];
 	        this.HasWind = source["HasWind"];
 	        this.RotSpeeds = source["RotSpeeds"];
 	        this.WindSpeeds = source["WindSpeeds"];
 	        this.Lines = this.convertValues(source["Lines"], Line);
+	        this.Options = this.convertValues(source["Options"], Options);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -108,6 +124,14 @@ export namespace diagram {
 	    MaxFreq: number;
 	    Cluster: boolean;
 	    FilterStruct: boolean;
+	    Metric: string;
+	    FreqWeight: number;
+	    DampWeight: number;
+	    MinCorrelation: number;
+	    Tracking: string;
+	    LookAhead: number;
+	    ConfidenceMargin: number;
+	    Seed: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Options(source);
@@ -119,8 +143,444 @@ export namespace diagram {
 	        this.MaxFreq = source["MaxFreq"];
 	        this.Cluster = source["Cluster"];
 	        this.FilterStruct = source["FilterStruct"];
+	        this.Metric = source["Metric"];
+	        this.FreqWeight = source["FreqWeight"];
+	        this.DampWeight = source["DampWeight"];
+	        this.MinCorrelation = source["MinCorrelation"];
+	        this.Tracking = source["Tracking"];
+	        this.LookAhead = source["LookAhead"];
+	        this.ConfidenceMargin = source["ConfidenceMargin"];
+	        this.Seed = source["Seed"];
 	    }
 	}
+	export class CompareOptions {
+	    OPTolerance: number;
+	    MinScore: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.OPTolerance = source["OPTolerance"];
+	        this.MinScore = source["MinScore"];
+	    }
+	}
+	export class PointDelta {
+	    OP: number[];
+	    RotSpeed: number;
+	    WindSpeed: number;
+	    NaturalFreqHz: number[];
+	    DampingRatio: number[];
+	    MAC: number;
+	    FreqDeltaHz: number;
+	    FreqDeltaPct: number;
+	    DampingDelta: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PointDelta(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.OP = source["OP"];
+	        this.RotSpeed = source["RotSpeed"];
+	        this.WindSpeed = source["WindSpeed"];
+	        this.NaturalFreqHz = source["NaturalFreqHz"];
+	        this.DampingRatio = source["DampingRatio"];
+	        this.MAC = source["MAC"];
+	        this.FreqDeltaHz = source["FreqDeltaHz"];
+	        this.FreqDeltaPct = source["FreqDeltaPct"];
+	        this.DampingDelta = source["DampingDelta"];
+	    }
+	}
+	export class LineMatch {
+	    Lines: number[];
+	    Labels: string[];
+	    Score: number;
+	    MeanFreqDeltaHz: number;
+	    MaxFreqDeltaHz: number;
+	    MeanDampingDelta: number;
+	    Deltas: PointDelta[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LineMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Lines = source["Lines"];
+	        this.Labels = source["Labels"];
+	        this.Score = source["Score"];
+	        this.MeanFreqDeltaHz = source["MeanFreqDeltaHz"];
+	        this.MaxFreqDeltaHz = source["MaxFreqDeltaHz"];
+	        this.MeanDampingDelta = source["MeanDampingDelta"];
+	        this.Deltas = this.convertValues(source["Deltas"], PointDelta);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OPPair {
+	    OP: number[];
+	    RotSpeed: number[];
+	    WindSpeed: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OPPair(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.OP = source["OP"];
+	        this.RotSpeed = source["RotSpeed"];
+	        this.WindSpeed = source["WindSpeed"];
+	    }
+	}
+	export class OverlayLine {
+	    Diagram: number;
+	    Match: number;
+	    Line: Line;
+	
+	    static createFrom(source: any = {}) {
+	        return new OverlayLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Diagram = source["Diagram"];
+	        this.Match = source["Match"];
+	        this.Line = this.convertValues(source["Line"], Line);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Overlay {
+	    HasWind: boolean;
+	    Lines: OverlayLine[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Overlay(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.HasWind = source["HasWind"];
+	        this.Lines = this.convertValues(source["Lines"], OverlayLine);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Comparison {
+	    UsedMAC: boolean;
+	    OPs: OPPair[];
+	    Matches: LineMatch[];
+	    Unmatched: number[][];
+	    Overlay: Overlay;
+	
+	    static createFrom(source: any = {}) {
+	        return new Comparison(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.UsedMAC = source["UsedMAC"];
+	        this.OPs = this.convertValues(source["OPs"], OPPair);
+	        this.Matches = this.convertValues(source["Matches"], LineMatch);
+	        this.Unmatched = source["Unmatched"];
+	        this.Overlay = this.convertValues(source["Overlay"], Overlay);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LineSwap {
+	    OP: number;
+	    Line: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LineSwap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.OP = source["OP"];
+	        this.Line = source["Line"];
+	    }
+	}
+	export class LineStyle {
+	    Label: string;
+	    Color: string;
+	    Dash: number[];
+	    Hidden: boolean;
+	    Speeds: number[];
+	    NaturalFreqHz: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LineStyle(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Label = source["Label"];
+	        this.Color = source["Color"];
+	        this.Dash = source["Dash"];
+	        this.Hidden = source["Hidden"];
+	        this.Speeds = source["Speeds"];
+	        this.NaturalFreqHz = source["NaturalFreqHz"];
+	    }
+	}
+	export class StyleTemplate {
+	    HasWind: boolean;
+	    Lines: LineStyle[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StyleTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.HasWind = source["HasWind"];
+	        this.Lines = this.convertValues(source["Lines"], LineStyle);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Units {
+	    Frequency: string;
+	    Damping: string;
+	    Damped: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Units(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Frequency = source["Frequency"];
+	        this.Damping = source["Damping"];
+	        this.Damped = source["Damped"];
+	    }
+	}
+	export class SeriesPoint {
+	    OpPtID: number;
+	    ModeID: number;
+	    RotSpeed: number;
+	    WindSpeed: number;
+	    Frequency: number;
+	    Damping: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SeriesPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.OpPtID = source["OpPtID"];
+	        this.ModeID = source["ModeID"];
+	        this.RotSpeed = source["RotSpeed"];
+	        this.WindSpeed = source["WindSpeed"];
+	        this.Frequency = source["Frequency"];
+	        this.Damping = source["Damping"];
+	    }
+	}
+	export class SeriesLine {
+	    ID: number;
+	    Label: string;
+	    Hidden: boolean;
+	    Points: SeriesPoint[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SeriesLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Label = source["Label"];
+	        this.Hidden = source["Hidden"];
+	        this.Points = this.convertValues(source["Points"], SeriesPoint);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Series {
+	    Units: Units;
+	    HasWind: boolean;
+	    FrequencyLabel: string;
+	    DampingLabel: string;
+	    RotorFreq: number[];
+	    Lines: SeriesLine[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Series(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Units = this.convertValues(source["Units"], Units);
+	        this.HasWind = source["HasWind"];
+	        this.FrequencyLabel = source["FrequencyLabel"];
+	        this.DampingLabel = source["DampingLabel"];
+	        this.RotorFreq = source["RotorFreq"];
+	        this.Lines = this.convertValues(source["Lines"], SeriesLine);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RenderOptions {
+	    Width: number;
+	    Height: number;
+	    Title: string;
+	    Units: Units;
+	    WindSpeed: boolean;
+	    Harmonics: number[];
+	    ShowHidden: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RenderOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Width = source["Width"];
+	        this.Height = source["Height"];
+	        this.Title = source["Title"];
+	        this.Units = this.convertValues(source["Units"], Units);
+	        this.WindSpeed = source["WindSpeed"];
+	        this.Harmonics = source["Harmonics"];
+	        this.ShowHidden = source["ShowHidden"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -242,6 +702,38 @@ export namespace lin {
 	        this.DampingRatio = source["DampingRatio"];
 	    }
 	}
+	export class AmbiguousGroup {
+	    Name: string;
+	    Files: string[];
+	    Reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AmbiguousGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.Files = source["Files"];
+	        this.Reason = source["Reason"];
+	    }
+	}
+	export class DiscoverOptions {
+	    Recursive: boolean;
+	    Patterns: string[];
+	    GroupBy: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscoverOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Recursive = source["Recursive"];
+	        this.Patterns = source["Patterns"];
+	        this.GroupBy = source["GroupBy"];
+	    }
+	}
 
 }
 
@@ -1293,6 +1785,7 @@ export namespace main {
 	export class Info {
 	    Date: string;
 	    Path: string;
+	    SchemaVersion: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Info(source);
@@ -1302,6 +1795,7 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.Date = source["Date"];
 	        this.Path = source["Path"];
+	        this.SchemaVersion = source["SchemaVersion"];
 	    }
 	}
 	
@@ -1364,10 +1858,14 @@ export namespace main {
 		}
 	}
 	export class Results {
+	    SchemaVersion: number;
 	    LinDir: string;
 	    HasWind: boolean;
 	    OPs: OperatingPoint[];
 	    LinOPs: lin.LinOP[];
+	    Ambiguous: lin.AmbiguousGroup[];
+	    Discover: lin.DiscoverOptions;
+	    FileKey: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Results(source);
@@ -1375,10 +1873,14 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.SchemaVersion = source["SchemaVersion"];
 	        this.LinDir = source["LinDir"];
 	        this.HasWind = source["HasWind"];
 	        this.OPs = this.convertValues(source["OPs"], OperatingPoint);
 	        this.LinOPs = this.convertValues(source["LinOPs"], lin.LinOP);
+	        this.Ambiguous = this.convertValues(source["Ambiguous"], lin.AmbiguousGroup);
+	        this.Discover = this.convertValues(source["Discover"], lin.DiscoverOptions);
+	        this.FileKey = source["FileKey"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1401,6 +1903,7 @@ export namespace main {
 	}
 	export class LinDirData {
 	    Dir: string;
+	    Run?: Run;
 	    Results?: Results;
 	    Diagram?: diagram.Diagram;
 	
@@ -1411,6 +1914,7 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.Dir = source["Dir"];
+	        this.Run = this.convertValues(source["Run"], Run);
 	        this.Results = this.convertValues(source["Results"], Results);
 	        this.Diagram = this.convertValues(source["Diagram"], diagram.Diagram);
 	    }
@@ -1472,6 +1976,144 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class BundleOptions {
+	    CaseIDs: number[];
+	    IncludeLinFiles: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BundleOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.CaseIDs = source["CaseIDs"];
+	        this.IncludeLinFiles = source["IncludeLinFiles"];
+	    }
+	}
+	export class UndoState {
+	    LinDir: string;
+	    Analysis?: Analysis;
+	    Evaluate?: Evaluate;
+	    Diagram?: diagram.Diagram;
+	    CanUndo: boolean;
+	    CanRedo: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UndoState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.LinDir = source["LinDir"];
+	        this.Analysis = this.convertValues(source["Analysis"], Analysis);
+	        this.Evaluate = this.convertValues(source["Evaluate"], Evaluate);
+	        this.Diagram = this.convertValues(source["Diagram"], diagram.Diagram);
+	        this.CanUndo = source["CanUndo"];
+	        this.CanRedo = source["CanRedo"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Run {
+	    ID: number;
+	    CaseID: number;
+	    Label: string;
+	    Dir: string;
+	    Date: string;
+	    ExecVersion: string;
+	    Case: Case;
+	    Overrides: Record<string, any>;
+	    Evaluate: Evaluate;
+	
+	    static createFrom(source: any = {}) {
+	        return new Run(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.CaseID = source["CaseID"];
+	        this.Label = source["Label"];
+	        this.Dir = source["Dir"];
+	        this.Date = source["Date"];
+	        this.ExecVersion = source["ExecVersion"];
+	        this.Case = this.convertValues(source["Case"], Case);
+	        this.Overrides = source["Overrides"];
+	        this.Evaluate = this.convertValues(source["Evaluate"], Evaluate);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CompareSource {
+	    CaseID: number;
+	    RunID: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareSource(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.CaseID = source["CaseID"];
+	        this.RunID = source["RunID"];
+	    }
+	}
+	export class ProcessStatus {
+	    Stage: string;
+	    OP: string;
+	    NumFiles: number;
+	    NumFilesRead: number;
+	    NumOPs: number;
+	    NumOPsDone: number;
+	    Error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcessStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Stage = source["Stage"];
+	        this.OP = source["OP"];
+	        this.NumFiles = source["NumFiles"];
+	        this.NumFilesRead = source["NumFilesRead"];
+	        this.NumOPs = source["NumOPs"];
+	        this.NumOPsDone = source["NumOPsDone"];
+	        this.Error = source["Error"];
+	    }
+	}
 	
 	
 	
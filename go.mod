@@ -8,8 +8,8 @@ require (
 	github.com/dominikbraun/graph v0.23.0
 	github.com/labstack/gommon v0.4.2
 	github.com/mkmik/argsort v1.1.0
-	github.com/parallelo-ai/kmeans v0.1.1
 	github.com/wailsapp/wails/v2 v2.10.1
+	golang.org/x/image v0.25.0
 	golang.org/x/sync v0.14.0
 	gonum.org/v1/gonum v0.16.0
 )
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"acdc/diagram"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// NumBackups is the number of previous versions kept when a file is saved
+const NumBackups = 5
+
+// MaxHistory is the maximum number of project states kept for undo/redo
+const MaxHistory = 50
+
+// saveFile writes the data to path if it differs from the current contents.
+// Previous versions of the file are kept as rotating backups named
+// 'path.N.bak' where N=1 is the most recent.
+func saveFile(path string, bs []byte) error {
+
+	// If file contents are unchanged, return
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, bs) {
+		return nil
+	}
+
+	// Rotate backups of existing file
+	if err := rotateBackups(path, NumBackups); err != nil {
+		return err
+	}
+
+	// Write file
+	return writeFileAtomic(path, bs)
+}
+
+// backupPath returns the path to the nth backup of the file
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d.bak", path, n)
+}
+
+// rotateBackups shifts existing backups of the file by one, removing the
+// oldest, and copies the file to the first backup.
+func rotateBackups(path string, numBackups int) error {
+
+	// If file doesn't exist, nothing to back up
+	bs, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading '%s': %w", path, err)
+	}
+
+	// Shift existing backups, oldest is overwritten
+	for i := numBackups - 1; i > 0; i-- {
+		err := os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error rotating backups of '%s': %w", path, err)
+		}
+	}
+
+	// Copy file to first backup
+	if err := writeFileAtomic(backupPath(path, 1), bs); err != nil {
+		return fmt.Errorf("error backing up '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory
+// and renames it to path, so path either contains the previous data or
+// the new data even if the application exits during the write.
+func writeFileAtomic(path string, bs []byte) error {
+
+	// Create temporary file in destination directory
+	f, err := createTempFile(path)
+	if err != nil {
+		return fmt.Errorf("error creating temporary file for '%s': %w", path, err)
+	}
+	tmpPath := f.Name()
+
+	// Write data and flush to disk, remove temporary file on error
+	if _, err = f.Write(bs); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing '%s': %w", path, err)
+	}
+
+	// Replace destination file with temporary file
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// createTempFile creates a new file named '.base.tmpN' in the directory of
+// path. Unlike os.CreateTemp, the file is created with mode 0666 so it gets
+// the same permissions (subject to umask) as a file written directly.
+func createTempFile(path string) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	for range 100 {
+		tmpPath := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10)
+		f, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: prefix + "*", Err: os.ErrExist}
+}
+
+//------------------------------------------------------------------------------
+// Undo/Redo History
+//------------------------------------------------------------------------------
+
+// History is a bounded list of encoded states with a cursor for undo/redo
+type History struct {
+	states [][]byte
+	index  int
+	max    int
+}
+
+func NewHistory(max int) *History {
+	return &History{index: -1, max: max}
+}
+
+// Push adds a state after the current state, discarding any states which
+// could have been redone. If the state matches the current state, it's ignored.
+func (h *History) Push(state []byte) {
+
+	// If state is unchanged, return
+	if h.index >= 0 && bytes.Equal(h.states[h.index], state) {
+		return
+	}
+
+	// Remove states after current and add new state
+	h.states = append(h.states[:h.index+1], state)
+
+	// Remove oldest states if history is too long
+	if n := len(h.states) - h.max; n > 0 {
+		h.states = h.states[n:]
+	}
+
+	h.index = len(h.states) - 1
+}
+
+func (h *History) CanUndo() bool {
+	return h.index > 0
+}
+
+func (h *History) CanRedo() bool {
+	return h.index < len(h.states)-1
+}
+
+// Undo moves the cursor to the previous state and returns it
+func (h *History) Undo() ([]byte, bool) {
+	if !h.CanUndo() {
+		return nil, false
+	}
+	h.index--
+	return h.states[h.index], true
+}
+
+// Redo moves the cursor to the next state and returns it
+func (h *History) Redo() ([]byte, bool) {
+	if !h.CanRedo() {
+		return nil, false
+	}
+	h.index++
+	return h.states[h.index], true
+}
+
+// UndoState contains the parts of the project restored by undo/redo
+type UndoState struct {
+	LinDir   string           `json:"LinDir"`
+	Analysis *Analysis        `json:"Analysis"`
+	Evaluate *Evaluate        `json:"Evaluate"`
+	Diagram  *diagram.Diagram `json:"Diagram"`
+	CanUndo  bool             `json:"CanUndo"`
+	CanRedo  bool             `json:"CanRedo"`
+}
+
+// recordHistory adds the current project state to the undo history
+func (p *Project) recordHistory() error {
+
+	if p.history == nil {
+		p.history = NewHistory(MaxHistory)
+	}
+
+	state := UndoState{
+		Analysis: p.Analysis,
+		Evaluate: p.Evaluate,
+		Diagram:  p.Diagram,
+	}
+	if p.Results != nil {
+		state.LinDir = p.Results.LinDir
+	}
+
+	bs, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("error encoding project state: %w", err)
+	}
+	p.history.Push(bs)
+
+	return nil
+}
+
+// UndoState returns the current project state and whether undo/redo is possible
+func (p *Project) UndoState() *UndoState {
+	state := &UndoState{
+		Analysis: p.Analysis,
+		Evaluate: p.Evaluate,
+		Diagram:  p.Diagram,
+	}
+	if p.Results != nil {
+		state.LinDir = p.Results.LinDir
+	}
+	if p.history != nil {
+		state.CanUndo = p.history.CanUndo()
+		state.CanRedo = p.history.CanRedo()
+	}
+	return state
+}
+
+// Undo restores the previous project state and saves the project
+func (p *Project) Undo() (*UndoState, error) {
+	if p.history == nil || !p.history.CanUndo() {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	bs, _ := p.history.Undo()
+	return p.restore(bs)
+}
+
+// Redo restores the next project state and saves the project
+func (p *Project) Redo() (*UndoState, error) {
+	if p.history == nil || !p.history.CanRedo() {
+		return nil, fmt.Errorf("nothing to redo")
+	}
+	bs, _ := p.history.Redo()
+	return p.restore(bs)
+}
+
+// restore sets the project state from the encoded state and saves the project.
+// The diagram is only restored if it belongs to the currently loaded results.
+func (p *Project) restore(bs []byte) (*UndoState, error) {
+
+	state := UndoState{}
+	if err := json.Unmarshal(bs, &state); err != nil {
+		return nil, fmt.Errorf("error decoding project state: %w", err)
+	}
+
+	p.Analysis = state.Analysis
+	p.Evaluate = state.Evaluate
+	if p.Results != nil && p.Results.LinDir == state.LinDir {
+		p.Diagram = state.Diagram
+	}
+
+	// Save project without recording the restored state
+	if err := p.write(); err != nil {
+		return nil, err
+	}
+
+	return p.UndoState(), nil
+}
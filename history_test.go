@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveFileBackups(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "project.json")
+
+	// Save more versions than the number of backups
+	for i := 0; i < NumBackups+3; i++ {
+		if err := saveFile(path, []byte(fmt.Sprint(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Saving unchanged contents shouldn't rotate backups
+	if err := saveFile(path, []byte(fmt.Sprint(NumBackups+2))); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := string(bs), fmt.Sprint(NumBackups+2); act != exp {
+		t.Fatalf("file contents = %v, expected %v", act, exp)
+	}
+	for n := 1; n <= NumBackups; n++ {
+		bs, err := os.ReadFile(backupPath(path, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if act, exp := string(bs), fmt.Sprint(NumBackups+2-n); act != exp {
+			t.Fatalf("backup %d contents = %v, expected %v", n, act, exp)
+		}
+	}
+	if _, err := os.Stat(backupPath(path, NumBackups+1)); !os.IsNotExist(err) {
+		t.Fatalf("backup %d should not exist", NumBackups+1)
+	}
+
+	// Only the file and backups should be in the directory
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(entries), NumBackups+1; act != exp {
+		t.Fatalf("len(entries) = %v, expected %v", act, exp)
+	}
+}
+
+func TestProjectUndoRedo(t *testing.T) {
+
+	// Create and save project
+	p := NewProject()
+	p.Info.Path = filepath.Join(t.TempDir(), "project.json")
+	p.Analysis = NewAnalysis()
+	if _, err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change case name and save
+	p.Analysis.Cases[0].Name = "Changed"
+	if _, err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Undo should restore original name
+	state, err := p.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := state.Analysis.Cases[0].Name, "Base"; act != exp {
+		t.Fatalf("case name = %v, expected %v", act, exp)
+	}
+	if state.CanUndo || !state.CanRedo {
+		t.Fatalf("CanUndo = %v, CanRedo = %v", state.CanUndo, state.CanRedo)
+	}
+
+	// Restored state should be saved to file
+	loaded, err := LoadProject(p.Info.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := loaded.Analysis.Cases[0].Name, "Base"; act != exp {
+		t.Fatalf("saved case name = %v, expected %v", act, exp)
+	}
+
+	// Redo should restore changed name
+	state, err = p.Redo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := state.Analysis.Cases[0].Name, "Changed"; act != exp {
+		t.Fatalf("case name = %v, expected %v", act, exp)
+	}
+	if _, err := p.Redo(); err == nil {
+		t.Fatal("expected error with nothing to redo")
+	}
+}
+
+func TestHistoryMax(t *testing.T) {
+	h := NewHistory(3)
+	for i := 0; i < 5; i++ {
+		h.Push([]byte(fmt.Sprint(i)))
+	}
+	for _, exp := range []string{"3", "2"} {
+		bs, ok := h.Undo()
+		if !ok {
+			t.Fatal("expected undo")
+		}
+		if act := string(bs); act != exp {
+			t.Fatalf("undo = %v, expected %v", act, exp)
+		}
+	}
+	if h.CanUndo() {
+		t.Fatal("history should be limited to 3 states")
+	}
+}
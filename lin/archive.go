@@ -0,0 +1,306 @@
+package lin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Linearization files may be gzip compressed (".lin.gz") or stored inside
+// zip or tar.gz archives. A file inside an archive is identified by the path
+// of the archive joined with the path of the file in the archive, as if the
+// archive were a directory, e.g. "study/lin.zip/ws10/op.1.lin".
+
+// archiveExts are the file extensions of supported archives
+var archiveExts = []string{".zip", ".tar.gz", ".tgz"}
+
+// isArchive returns true if the file name has an archive extension.
+func isArchive(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitArchivePath returns the path of the archive containing filePath and
+// the slash separated path of the file inside the archive. If filePath isn't
+// inside an archive, ok is false.
+func SplitArchivePath(filePath string) (archivePath, memberPath string, ok bool) {
+	for dir := filepath.Dir(filePath); ; dir = filepath.Dir(dir) {
+		if isArchive(dir) {
+			if fi, err := os.Stat(dir); err == nil && fi.Mode().IsRegular() {
+				rel, err := filepath.Rel(dir, filePath)
+				if err != nil {
+					return "", "", false
+				}
+				return dir, filepath.ToSlash(rel), true
+			}
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return "", "", false
+		}
+	}
+}
+
+// OpenLinFile opens the linearization file at filePath for reading. Files
+// ending in ".gz" are decompressed and files inside zip or tar.gz archives
+// are read from the archive without extracting it.
+func OpenLinFile(filePath string) (io.ReadCloser, error) {
+
+	// Open file in archive or on disk
+	var rc io.ReadCloser
+	var err error
+	if archivePath, memberPath, ok := SplitArchivePath(filePath); ok {
+		rc, err = openArchiveMember(archivePath, memberPath)
+	} else {
+		rc, err = os.Open(filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Decompress gzip file
+	if strings.HasSuffix(strings.ToLower(filePath), ".gz") {
+		gz, err := gzip.NewReader(rc)
+		if err != nil {
+			rc.Close()
+			return nil, fmt.Errorf("error decompressing '%s': %w", filePath, err)
+		}
+		return &multiReadCloser{Reader: gz, closers: []io.Closer{rc, gz}}, nil
+	}
+
+	return rc, nil
+}
+
+// multiReadCloser reads from Reader and closes all closers, last to first,
+// when it's closed.
+type multiReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (mrc *multiReadCloser) Close() error {
+	var err error
+	for i := len(mrc.closers) - 1; i >= 0; i-- {
+		if cerr := mrc.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// listArchive returns the slash separated paths of the regular files in the
+// archive.
+func listArchive(archivePath string) ([]string, error) {
+
+	names := []string{}
+
+	// Zip archive
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening '%s': %w", archivePath, err)
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if name, ok := archiveMemberName(f.Name); ok && f.Mode().IsRegular() {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+
+	// Tar archive
+	f, tr, err := openTarGz(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading '%s': %w", archivePath, err)
+		}
+		if name, ok := archiveMemberName(hdr.Name); ok && hdr.Typeflag == tar.TypeReg {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// openArchiveMember opens the file with the slash separated path memberPath
+// inside the archive. A tar.gz archive is decompressed from the start to find
+// the file, so readTarGzFiles should be used to read many files from one.
+func openArchiveMember(archivePath, memberPath string) (io.ReadCloser, error) {
+
+	// Zip archive
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening '%s': %w", archivePath, err)
+		}
+		for _, f := range zr.File {
+			if name, ok := archiveMemberName(f.Name); !ok || name != memberPath {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				zr.Close()
+				return nil, fmt.Errorf("error opening '%s' in '%s': %w", memberPath, archivePath, err)
+			}
+			return &multiReadCloser{Reader: rc, closers: []io.Closer{zr, rc}}, nil
+		}
+		zr.Close()
+		return nil, fmt.Errorf("'%s' not found in '%s'", memberPath, archivePath)
+	}
+
+	// Tar archive, advance reader to the file
+	f, tr, err := openTarGz(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error reading '%s': %w", archivePath, err)
+		}
+		if name, ok := archiveMemberName(hdr.Name); ok && name == memberPath {
+			return &multiReadCloser{Reader: tr, closers: []io.Closer{f}}, nil
+		}
+	}
+	f.Close()
+	return nil, fmt.Errorf("'%s' not found in '%s'", memberPath, archivePath)
+}
+
+// isTarGz returns true if the archive is a gzip compressed tar archive,
+// which can only be read sequentially.
+func isTarGz(archivePath string) bool {
+	name := strings.ToLower(archivePath)
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// splitTarGzFiles separates the indices of the files inside tar.gz archives,
+// by archive path, from the indices of the other files.
+func splitTarGzFiles(filePaths []string) (archives map[string][]int, others []int) {
+	archives = map[string][]int{}
+	for i, filePath := range filePaths {
+		if archivePath, _, ok := SplitArchivePath(filePath); ok && isTarGz(archivePath) {
+			archives[archivePath] = append(archives[archivePath], i)
+		} else {
+			others = append(others, i)
+		}
+	}
+	return archives, others
+}
+
+// readTarGzFiles reads the files at the given indices of filePaths, which
+// must be inside the tar.gz archive, in one sequential pass over the archive.
+// Opening each file separately would decompress the archive from the start
+// every time. fn is called with the index and decompressed contents of each
+// file in archive order. It returns the context's cause if ctx is canceled.
+func readTarGzFiles(ctx context.Context, archivePath string, filePaths []string, indices []int, fn func(i int, r io.Reader) error) error {
+
+	// Map paths of files in archive to their indices
+	wanted := map[string]int{}
+	for _, i := range indices {
+		ap, memberPath, ok := SplitArchivePath(filePaths[i])
+		if !ok || ap != archivePath {
+			return fmt.Errorf("'%s' isn't in '%s'", filePaths[i], archivePath)
+		}
+		wanted[memberPath] = i
+	}
+
+	f, tr, err := openTarGz(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Read archive until all files have been found
+	for len(wanted) > 0 {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading '%s': %w", archivePath, err)
+		}
+		name, ok := archiveMemberName(hdr.Name)
+		if !ok || hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		i, ok := wanted[name]
+		if !ok {
+			continue
+		}
+		delete(wanted, name)
+
+		// Decompress gzip file
+		if !strings.HasSuffix(strings.ToLower(name), ".gz") {
+			err = fn(i, tr)
+		} else if gz, gzErr := gzip.NewReader(tr); gzErr != nil {
+			err = fmt.Errorf("error decompressing '%s': %w", filePaths[i], gzErr)
+		} else {
+			err = fn(i, gz)
+			gz.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Return error for first file not found
+	missing := slices.Sorted(maps.Keys(wanted))
+	if len(missing) > 0 {
+		return fmt.Errorf("'%s' not found in '%s'", missing[0], archivePath)
+	}
+
+	return nil
+}
+
+// openTarGz opens the gzip compressed tar archive. The returned closer must
+// be closed when done reading.
+func openTarGz(archivePath string) (io.Closer, *tar.Reader, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening '%s': %w", archivePath, err)
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("error decompressing '%s': %w", archivePath, err)
+	}
+	return &multiReadCloser{Reader: gz, closers: []io.Closer{f, gz}}, tar.NewReader(gz), nil
+}
+
+// archiveMemberName returns the cleaned name of a file in an archive. It
+// returns false if the name would be outside the archive.
+func archiveMemberName(name string) (string, bool) {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", false
+	}
+	return name, true
+}
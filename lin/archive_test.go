@@ -0,0 +1,183 @@
+package lin_test
+
+import (
+	"acdc/lin"
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadArchives(t *testing.T) {
+
+	const linPath = "testdata/StC_test_OC4Semi_Linear_Tow.1.lin"
+	bs, err := os.ReadFile(linPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read from reader matches reading from file
+	exp, err := lin.ReadLinFile(linPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ld, err := lin.ReadLin(bytes.NewReader(bs), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ld.Num_x != exp.Num_x || !reflect.DeepEqual(ld.OP_x, exp.OP_x) {
+		t.Fatal("data read from reader differs from file")
+	}
+
+	// Write gzip compressed file
+	dir := t.TempDir()
+	gzBuf := &bytes.Buffer{}
+	gw := gzip.NewWriter(gzBuf)
+	gw.Write(bs)
+	gw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "gz.1.lin.gz"), gzBuf.Bytes(), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write zip archive with files at top level and in a subdirectory
+	zipBuf := &bytes.Buffer{}
+	zw := zip.NewWriter(zipBuf)
+	for _, name := range []string{"zip.1.lin", "sub/zip.1.lin", "readme.txt"} {
+		w, _ := zw.Create(name)
+		w.Write(bs)
+	}
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "study.zip"), zipBuf.Bytes(), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write tar.gz archive containing a gzip compressed file
+	tgzBuf := &bytes.Buffer{}
+	tgw := gzip.NewWriter(tgzBuf)
+	tw := tar.NewWriter(tgw)
+	tw.WriteHeader(&tar.Header{Name: "./tar.1.lin.gz", Mode: 0666, Size: int64(gzBuf.Len()), Typeflag: tar.TypeReg})
+	tw.Write(gzBuf.Bytes())
+	tw.Close()
+	tgw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "study.tar.gz"), tgzBuf.Bytes(), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	// Discover files in top level of directory and archives
+	d, err := lin.Discover(context.Background(), dir, lin.NewDiscoverOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, fg := range d.Groups {
+		rel, _ := filepath.Rel(dir, fg.Name)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	if exp := []string{"gz", "study.tar.gz/tar", "study.zip/zip"}; !reflect.DeepEqual(names, exp) {
+		t.Fatalf("groups = %v, expected %v", names, exp)
+	}
+
+	// Recursive discovery includes subdirectories in archives
+	opts := lin.NewDiscoverOptions()
+	opts.Recursive = true
+	if d, err = lin.Discover(context.Background(), dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Groups) != 4 {
+		t.Fatalf("found %d groups, expected 4", len(d.Groups))
+	}
+
+	// Each discovered file reads the same data as the original file
+	for _, fg := range d.Groups {
+		ld, err := lin.ReadLinFile(fg.Files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ld.ID != 1 || !reflect.DeepEqual(ld.A, exp.A) {
+			t.Fatalf("data read from '%s' differs from original file", fg.Files[0])
+		}
+	}
+
+	// Files in archive are processed without writing a cache file
+	linOPs, err := lin.ProcessFileGroups(context.Background(), d.Groups[2:3], lin.NewProcessOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(linOPs) != 1 || len(linOPs[0].Modes) == 0 {
+		t.Fatal("expected modes from files in archive")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*_cache.bin")); len(matches) != 0 {
+		t.Fatalf("unexpected cache files %v", matches)
+	}
+
+	// Missing file in archive returns error
+	if _, err := lin.ReadLinFile(filepath.Join(dir, "study.zip", "missing.1.lin")); err == nil {
+		t.Fatal("expected error reading missing file in archive")
+	}
+}
+
+func TestReadTarGzGroup(t *testing.T) {
+
+	// Write azimuth files of one operating point to tar.gz archive in
+	// reverse order, along with an unrelated file
+	src := t.TempDir()
+	names := []string{"op.3.lin", "op.2.lin", "op.1.lin"}
+	tgzBuf := &bytes.Buffer{}
+	tgw := gzip.NewWriter(tgzBuf)
+	tw := tar.NewWriter(tgw)
+	tw.WriteHeader(&tar.Header{Name: "readme.txt", Mode: 0666, Size: 2, Typeflag: tar.TypeReg})
+	tw.Write([]byte("hi"))
+	for i, name := range names {
+		writeLinFile(t, filepath.Join(src, name), 1.0, 2.0944*float64(2-i))
+		bs, err := os.ReadFile(filepath.Join(src, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0666, Size: int64(len(bs)), Typeflag: tar.TypeReg})
+		tw.Write(bs)
+	}
+	tw.Close()
+	tgw.Close()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "study.tgz"), tgzBuf.Bytes(), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	// Discover and process group of files in archive
+	d, err := lin.Discover(context.Background(), dir, lin.NewDiscoverOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Groups) != 1 || len(d.Groups[0].Files) != 3 {
+		t.Fatalf("groups = %+v, expected one group with 3 files", d.Groups)
+	}
+	numRead := 0
+	opts := lin.NewProcessOptions()
+	opts.Progress = func(p lin.ProcessProgress) {
+		if p.Stage == lin.StageReading {
+			numRead++
+		}
+	}
+	linOPs, err := lin.ProcessFileGroups(context.Background(), d.Groups, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(linOPs) != 1 || len(linOPs[0].Modes) == 0 {
+		t.Fatal("expected modes from files in archive")
+	}
+	if numRead != 3 {
+		t.Fatalf("read %d files, expected 3", numRead)
+	}
+
+	// Missing file in group returns error
+	fg := d.Groups[0]
+	fg.Files = append(fg.Files, filepath.Join(dir, "study.tgz", "op.4.lin"))
+	if _, err := lin.ProcessFileGroups(context.Background(), []lin.FileGroup{fg}, opts); err == nil {
+		t.Fatal("expected error reading missing file in archive")
+	}
+}
@@ -0,0 +1,163 @@
+package lin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// cacheVersion must be incremented when a change to processing would produce
+// different results from the same linearization files, so cached results
+// from previous versions are recomputed.
+const cacheVersion = 3
+
+// opCache is written next to an operating point's linearization files and
+// contains its results along with the key of the files they were computed from.
+type opCache struct {
+	Key   string
+	MBC   *MBC
+	Modes Modes
+}
+
+// cachePath returns the path of the cache file for the file group.
+func (fg *FileGroup) cachePath() string {
+	return fg.Name + "_cache.bin"
+}
+
+// inArchive returns true if the group's files are inside an archive, where
+// the cache file can't be written.
+func (fg *FileGroup) inArchive() bool {
+	_, _, ok := SplitArchivePath(fg.Files[0])
+	return ok
+}
+
+// cacheOptions contains the processing options which change the results
+// computed from the same files. NumCPUs, UseCache, and Progress only change
+// how files are processed so they aren't included. Options added to
+// ProcessOptions which change the results must be added here so changing
+// them invalidates cached results.
+type cacheOptions struct{}
+
+// cacheOptions returns the options which are included in the cache key.
+func (opts ProcessOptions) cacheOptions() cacheOptions {
+	return cacheOptions{}
+}
+
+// cacheKey returns a hash of the processing version, the processing options
+// which affect the results, and the names and contents of the linearization
+// files in the group.
+func (fg *FileGroup) cacheKey(opts ProcessOptions) (string, error) {
+
+	// Sort files by name so the key doesn't depend on file order
+	files := append([]string{}, fg.Files...)
+	sort.Strings(files)
+
+	// Hash version and options, then name and contents of each file
+	h := sha256.New()
+	fmt.Fprintf(h, "version %d\n", cacheVersion)
+	fmt.Fprintf(h, "options %+v\n", opts.cacheOptions())
+	for _, path := range files {
+		fh := sha256.New()
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("error opening '%s': %w", path, err)
+		}
+		_, err = io.Copy(fh, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("error reading '%s': %w", path, err)
+		}
+		fmt.Fprintf(h, "%s %x\n", filepath.Base(path), fh.Sum(nil))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadCached returns the operating point results previously cached for the
+// file group if the cache key matches. It returns nil if the cache is missing,
+// outdated, or can't be read.
+func (fg *FileGroup) loadCached(key string) *LinOP {
+
+	// Read cache file and compare key
+	f, err := os.Open(fg.cachePath())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	cache := opCache{}
+	if err := gob.NewDecoder(f).Decode(&cache); err != nil || cache.Key != key || cache.MBC == nil {
+		return nil
+	}
+
+	return &LinOP{
+		RootPath:      fg.Name,
+		FilePaths:     fg.Files,
+		HasAeroStates: cache.MBC.hasAeroStates(),
+		MBC:           cache.MBC,
+		Modes:         cache.Modes,
+	}
+}
+
+// writeCache writes the operating point results to the group's cache file
+// with the given key.
+func (fg *FileGroup) writeCache(key string, linOP *LinOP) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(opCache{Key: key, MBC: linOP.MBC, Modes: linOP.Modes}); err != nil {
+		return fmt.Errorf("error encoding cache: %w", err)
+	}
+	return writeFileAtomic(fg.cachePath(), buf.Bytes())
+}
+
+// writeFileAtomic writes the data to a temporary file in the destination
+// directory then renames it to path, so readers never see a partial file.
+func writeFileAtomic(path string, bs []byte) error {
+
+	// Create temporary file in destination directory
+	f, err := createTempFile(path)
+	if err != nil {
+		return fmt.Errorf("error creating temporary file for '%s': %w", path, err)
+	}
+	tmpPath := f.Name()
+
+	// Write data, remove temporary file on error
+	if _, err = f.Write(bs); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing '%s': %w", path, err)
+	}
+
+	// Replace destination file with temporary file
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// createTempFile creates a new file named '.base.tmpN' in the directory of
+// path with mode 0666 (subject to umask), as os.CreateTemp uses mode 0600.
+func createTempFile(path string) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	for range 100 {
+		tmpPath := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10)
+		f, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: prefix + "*", Err: os.ErrExist}
+}
@@ -0,0 +1,185 @@
+package lin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The results container stores the MBC data and modes of every operating
+// point of a case in one file. The header contains an index of the position
+// of each operating point's record so they can be read individually:
+//
+//	magic    [8]byte  "ACDCRES\x00"
+//	version  uint32
+//	numOPs   uint32
+//	key      [16]byte random, shared with the results JSON file
+//	index    [numOPs]{offset, size uint64}
+//	records  [numOPs]gob encoded opRecord
+//
+// All integers are little endian and offsets are from the start of the file.
+// The key is generated each time the file is written and stored with the
+// index of operating points in the results JSON file, so a container which
+// doesn't belong with the JSON file is detected when it's opened.
+
+// ResultsFileVersion is the current version of the results container format
+const ResultsFileVersion = 2
+
+var resultsFileMagic = [8]byte{'A', 'C', 'D', 'C', 'R', 'E', 'S', 0}
+
+// resultsFileHeader is the fixed size header at the start of the container
+type resultsFileHeader struct {
+	Magic   [8]byte
+	Version uint32
+	NumOPs  uint32
+	Key     [16]byte
+}
+
+// resultsFileEntry is the position of an operating point record in the container
+type resultsFileEntry struct {
+	Offset uint64
+	Size   uint64
+}
+
+// opRecord is the operating point data stored in the container. The file
+// paths are stored in the results JSON file so they can be rebased.
+//
+// Records are gob encodings of MBC and Modes, so ResultsFileVersion must be
+// incremented when the fields of opRecord, MBC, Modes, or the types they
+// contain change, so containers written by previous versions are rejected
+// rather than decoded with missing or misinterpreted fields.
+type opRecord struct {
+	MBC   *MBC
+	Modes Modes
+}
+
+// WriteResultsFile writes the MBC data, including the averaged matrices and
+// orderings, and the modes, including eigenvectors, of each operating point
+// to w in the results container format. It returns the key of the file,
+// which ResultsFile.Key returns when the file is opened.
+func WriteResultsFile(w io.Writer, linOPs []LinOP) (string, error) {
+
+	// Encode operating point records
+	records := make([][]byte, len(linOPs))
+	for i, linOP := range linOPs {
+		buf := &bytes.Buffer{}
+		if err := gob.NewEncoder(buf).Encode(opRecord{MBC: linOP.MBC, Modes: linOP.Modes}); err != nil {
+			return "", fmt.Errorf("error encoding operating point %d: %w", i, err)
+		}
+		records[i] = buf.Bytes()
+	}
+
+	// Build index of record positions which follow the header and index
+	index := make([]resultsFileEntry, len(records))
+	offset := uint64(binary.Size(resultsFileHeader{}) + binary.Size(index))
+	for i, rec := range records {
+		index[i] = resultsFileEntry{Offset: offset, Size: uint64(len(rec))}
+		offset += uint64(len(rec))
+	}
+
+	// Write header with new key, index and records
+	header := resultsFileHeader{
+		Magic:   resultsFileMagic,
+		Version: ResultsFileVersion,
+		NumOPs:  uint32(len(records)),
+	}
+	if _, err := rand.Read(header.Key[:]); err != nil {
+		return "", fmt.Errorf("error generating results key: %w", err)
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return "", fmt.Errorf("error writing results header: %w", err)
+	}
+	if err := binary.Write(w, binary.LittleEndian, index); err != nil {
+		return "", fmt.Errorf("error writing results index: %w", err)
+	}
+	for i, rec := range records {
+		if _, err := w.Write(rec); err != nil {
+			return "", fmt.Errorf("error writing operating point %d: %w", i, err)
+		}
+	}
+
+	return hex.EncodeToString(header.Key[:]), nil
+}
+
+// ResultsFile provides access to the operating points in a results container
+// file. Only the index is read when it's opened, operating points are read
+// from the file as they're requested.
+type ResultsFile struct {
+	path  string
+	key   string
+	index []resultsFileEntry
+}
+
+// OpenResultsFile reads the header and index of the results container at path.
+func OpenResultsFile(path string) (*ResultsFile, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Read and check header
+	header := resultsFileHeader{}
+	if err := binary.Read(f, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("error reading results header: %w", err)
+	}
+	if header.Magic != resultsFileMagic {
+		return nil, fmt.Errorf("'%s' is not a results file", path)
+	}
+	if header.Version != ResultsFileVersion {
+		return nil, fmt.Errorf("unsupported results file version %d, expected %d",
+			header.Version, ResultsFileVersion)
+	}
+
+	// Read index
+	rf := &ResultsFile{
+		path:  path,
+		key:   hex.EncodeToString(header.Key[:]),
+		index: make([]resultsFileEntry, header.NumOPs),
+	}
+	if err := binary.Read(f, binary.LittleEndian, rf.index); err != nil {
+		return nil, fmt.Errorf("error reading results index: %w", err)
+	}
+
+	return rf, nil
+}
+
+// Key returns the key generated when the file was written.
+func (rf *ResultsFile) Key() string {
+	return rf.key
+}
+
+// NumOPs returns the number of operating points in the file.
+func (rf *ResultsFile) NumOPs() int {
+	return len(rf.index)
+}
+
+// ReadOP reads the MBC data and modes of operating point i from the file.
+func (rf *ResultsFile) ReadOP(i int) (*MBC, Modes, error) {
+
+	if i < 0 || i >= len(rf.index) {
+		return nil, nil, fmt.Errorf("invalid operating point index %d", i)
+	}
+
+	f, err := os.Open(rf.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	// Decode operating point record at its position in the file
+	entry := rf.index[i]
+	rec := opRecord{}
+	sr := io.NewSectionReader(f, int64(entry.Offset), int64(entry.Size))
+	if err := gob.NewDecoder(sr).Decode(&rec); err != nil {
+		return nil, nil, fmt.Errorf("error reading operating point %d from '%s': %w", i, rf.path, err)
+	}
+
+	return rec.MBC, rec.Modes, nil
+}
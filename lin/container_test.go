@@ -0,0 +1,78 @@
+package lin_test
+
+import (
+	"acdc/lin"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestResultsFile(t *testing.T) {
+
+	// Process operating points without cache so files aren't written to testdata
+	opts := lin.NewProcessOptions()
+	opts.UseCache = false
+	linOPs, err := lin.ProcessFiles(context.Background(), []string{
+		"testdata/StC_test_OC4Semi_Linear_Nac.1.lin",
+		"testdata/WP_Stationary_Linear.1.lin",
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write results file
+	buf := &bytes.Buffer{}
+	key, err := lin.WriteResultsFile(buf, linOPs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "results.bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	// Open results file and check number of OPs
+	rf, err := lin.OpenResultsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := rf.NumOPs(), len(linOPs); act != exp {
+		t.Fatalf("NumOPs() = %v, expected %v", act, exp)
+	}
+	if act, exp := rf.Key(), key; act != exp {
+		t.Fatalf("Key() = %v, expected %v", act, exp)
+	}
+
+	// Read OPs in reverse order and check they match the processed results
+	for i := rf.NumOPs() - 1; i >= 0; i-- {
+		mbc, modes, err := rf.ReadOP(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(modes, linOPs[i].Modes) {
+			t.Fatalf("modes of OP %d differ", i)
+		}
+		if !reflect.DeepEqual(mbc.OrderEigen, linOPs[i].MBC.OrderEigen) ||
+			!reflect.DeepEqual(mbc.DOFsEigen, linOPs[i].MBC.DOFsEigen) {
+			t.Fatalf("orderings of OP %d differ", i)
+		}
+		if !mat.Equal(mbc.AvgA, linOPs[i].MBC.AvgA) || !mat.Equal(mbc.AvgX, linOPs[i].MBC.AvgX) {
+			t.Fatalf("averaged matrices of OP %d differ", i)
+		}
+	}
+
+	// Invalid OP index returns error
+	if _, _, err := rf.ReadOP(rf.NumOPs()); err == nil {
+		t.Fatal("expected error reading invalid OP")
+	}
+
+	// File that isn't a results file returns error
+	if _, err := lin.OpenResultsFile("testdata/WP_Stationary_Linear.1.lin"); err == nil {
+		t.Fatal("expected error opening lin file as results file")
+	}
+}
@@ -0,0 +1,161 @@
+package lin
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// StateSpace is a linear system with states x, inputs u and outputs y:
+//
+//	dx/dt = A x + B u
+//	y     = C x + D u
+type StateSpace struct {
+	A, B, C, D *mat.Dense
+}
+
+// dims returns the number of states, inputs and outputs of the system and
+// an error if the matrix dimensions are inconsistent.
+func (ss *StateSpace) dims() (nx, nu, ny int, err error) {
+	if ss.A == nil || ss.B == nil || ss.C == nil || ss.D == nil {
+		return 0, 0, 0, fmt.Errorf("state space matrices must not be nil")
+	}
+	nx, _ = ss.A.Dims()
+	_, nu = ss.B.Dims()
+	ny, _ = ss.C.Dims()
+	if r, c := ss.A.Dims(); r != nx || c != nx {
+		return 0, 0, 0, fmt.Errorf("A is %dx%d, expected square", r, c)
+	}
+	if r, c := ss.B.Dims(); r != nx || c != nu {
+		return 0, 0, 0, fmt.Errorf("B is %dx%d, expected %dx%d", r, c, nx, nu)
+	}
+	if r, c := ss.C.Dims(); r != ny || c != nx {
+		return 0, 0, 0, fmt.Errorf("C is %dx%d, expected %dx%d", r, c, ny, nx)
+	}
+	if r, c := ss.D.Dims(); r != ny || c != nu {
+		return 0, 0, 0, fmt.Errorf("D is %dx%d, expected %dx%d", r, c, ny, nu)
+	}
+	return nx, nu, ny, nil
+}
+
+// AppendSystems returns the uncoupled system formed by placing the matrices
+// of each system on the block diagonal. States, inputs and outputs are in
+// the order of the systems, which must match the order of the module inputs
+// and outputs in the glue-code Jacobians.
+func AppendSystems(systems ...*StateSpace) (*StateSpace, error) {
+
+	// Get total number of states, inputs and outputs
+	var nx, nu, ny int
+	for i, ss := range systems {
+		x, u, y, err := ss.dims()
+		if err != nil {
+			return nil, fmt.Errorf("error in system %d: %w", i, err)
+		}
+		nx, nu, ny = nx+x, nu+u, ny+y
+	}
+	if nx == 0 || nu == 0 || ny == 0 {
+		return nil, fmt.Errorf("systems must have states, inputs and outputs")
+	}
+
+	// Copy each system's matrices into its block
+	out := &StateSpace{
+		A: mat.NewDense(nx, nx, nil),
+		B: mat.NewDense(nx, nu, nil),
+		C: mat.NewDense(ny, nx, nil),
+		D: mat.NewDense(ny, nu, nil),
+	}
+	var ix, iu, iy int
+	for _, ss := range systems {
+		x, u, y, _ := ss.dims()
+		copyBlock(out.A, ss.A, ix, ix)
+		copyBlock(out.B, ss.B, ix, iu)
+		copyBlock(out.C, ss.C, iy, ix)
+		copyBlock(out.D, ss.D, iy, iu)
+		ix, iu, iy = ix+x, iu+u, iy+y
+	}
+
+	return out, nil
+}
+
+// copyBlock copies src into dst with its top left corner at row i, column j.
+func copyBlock(dst, src *mat.Dense, i, j int) {
+	r, c := src.Dims()
+	if r > 0 && c > 0 {
+		dst.Slice(i, i+r, j, j+c).(*mat.Dense).Copy(src)
+	}
+}
+
+// Couple assembles the coupled system from the uncoupled module system and
+// the glue-code input/output Jacobians, as is done by OpenFAST. The module
+// inputs and outputs are related by the linearized constraint
+//
+//	dUdu u + dUdy y = r
+//
+// where r is the input of the coupled system. Eliminating u with
+// G = dUdu + dUdy D gives
+//
+//	A' = A - B G⁻¹ dUdy C    B' = B G⁻¹
+//	C' = C - D G⁻¹ dUdy C    D' = D G⁻¹
+//
+// A loop can be closed around an external controller by appending it to the
+// module system and adding its connections to dUdu and dUdy.
+func Couple(modules *StateSpace, dUdu, dUdy mat.Matrix) (*StateSpace, error) {
+
+	// Check dimensions
+	nx, nu, ny, err := modules.dims()
+	if err != nil {
+		return nil, err
+	}
+	if nx == 0 || nu == 0 || ny == 0 {
+		return nil, fmt.Errorf("module system must have states, inputs and outputs")
+	}
+	if r, c := dUdu.Dims(); r != nu || c != nu {
+		return nil, fmt.Errorf("dUdu is %dx%d, expected %dx%d", r, c, nu, nu)
+	}
+	if r, c := dUdy.Dims(); r != nu || c != ny {
+		return nil, fmt.Errorf("dUdy is %dx%d, expected %dx%d", r, c, nu, ny)
+	}
+
+	// Calculate inverse of G = dUdu + dUdy*D
+	G := mat.NewDense(nu, nu, nil)
+	G.Mul(dUdy, modules.D)
+	G.Add(G, dUdu)
+	Ginv := mat.NewDense(nu, nu, nil)
+	if err := Ginv.Inverse(G); err != nil {
+		return nil, fmt.Errorf("error inverting input/output coupling matrix: %w", err)
+	}
+
+	// Calculate H = G^-1*dUdy*C, the inputs resulting from the states
+	H := mat.NewDense(nu, nx, nil)
+	H.Product(Ginv, dUdy, modules.C)
+
+	// Assemble coupled matrices
+	out := &StateSpace{
+		A: mat.NewDense(nx, nx, nil),
+		B: mat.NewDense(nx, nu, nil),
+		C: mat.NewDense(ny, nx, nil),
+		D: mat.NewDense(ny, nu, nil),
+	}
+	out.A.Mul(modules.B, H)
+	out.A.Sub(modules.A, out.A)
+	out.B.Mul(modules.B, Ginv)
+	out.C.Mul(modules.D, H)
+	out.C.Sub(modules.C, out.C)
+	out.D.Mul(modules.D, Ginv)
+
+	return out, nil
+}
+
+// Couple assembles the coupled system at step i from the module system and
+// the input/output Jacobians read from the linearization files. The module
+// system is typically built with AppendSystems from the module level
+// linearization files written with LinOutMod.
+func (md *MatData) Couple(i int, modules *StateSpace) (*StateSpace, error) {
+	if md.DUdu == nil || md.DUdy == nil {
+		return nil, fmt.Errorf("linearization files don't contain input/output Jacobians, enable LinOutJac")
+	}
+	if i < 0 || i >= md.NumStep {
+		return nil, fmt.Errorf("invalid step %d", i)
+	}
+	return Couple(modules, md.DUdu[i], md.DUdy[i])
+}
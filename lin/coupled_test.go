@@ -0,0 +1,92 @@
+package lin_test
+
+import (
+	"acdc/lin"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestCouple(t *testing.T) {
+
+	// Plant dx/dt = -x + u, y = x, and lag controller with gain k and
+	// time constant 1/a
+	k, a := -2.0, 4.0
+	plant := &lin.StateSpace{
+		A: mat.NewDense(1, 1, []float64{-1}),
+		B: mat.NewDense(1, 1, []float64{1}),
+		C: mat.NewDense(1, 1, []float64{1}),
+		D: mat.NewDense(1, 1, []float64{0}),
+	}
+	controller := &lin.StateSpace{
+		A: mat.NewDense(1, 1, []float64{-a}),
+		B: mat.NewDense(1, 1, []float64{a}),
+		C: mat.NewDense(1, 1, []float64{k}),
+		D: mat.NewDense(1, 1, []float64{0}),
+	}
+	modules, err := lin.AppendSystems(plant, controller)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Connect plant input to controller output and controller input to
+	// plant output: u - [0 1; 1 0] y = r
+	dUdu := mat.NewDense(2, 2, []float64{1, 0, 0, 1})
+	dUdy := mat.NewDense(2, 2, []float64{0, -1, -1, 0})
+	ss, err := lin.Couple(modules, dUdu, dUdy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check closed loop state matrix
+	expA := mat.NewDense(2, 2, []float64{-1, k, a, -a})
+	if !mat.EqualApprox(ss.A, expA, 1e-12) {
+		t.Fatalf("A = %v, expected %v", mat.Formatted(ss.A), mat.Formatted(expA))
+	}
+	if !mat.EqualApprox(ss.B, modules.B, 1e-12) {
+		t.Fatalf("B = %v, expected %v", mat.Formatted(ss.B), mat.Formatted(modules.B))
+	}
+
+	// Feedthrough in controller is included through G
+	controller.D.Set(0, 0, 0.5)
+	if modules, err = lin.AppendSystems(plant, controller); err != nil {
+		t.Fatal(err)
+	}
+	if ss, err = lin.Couple(modules, dUdu, dUdy); err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := ss.A.At(0, 0), -1+0.5; act != exp {
+		t.Fatalf("A[0,0] = %v, expected %v", act, exp)
+	}
+
+	// Jacobians with wrong dimensions return error
+	if _, err := lin.Couple(modules, mat.NewDense(1, 1, []float64{1}), dUdy); err == nil {
+		t.Fatal("expected error for wrong dUdu dimensions")
+	}
+}
+
+func TestMatDataJacobians(t *testing.T) {
+
+	// Jacobians are carried into matrix data
+	ld, err := lin.ReadLinFile("testdata/Ideal_Beam_Fixed_Free_Linear.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := lin.NewMatData([]*lin.LinData{ld})
+	if len(md.DUdu) != 1 || len(md.DUdy) != 1 {
+		t.Fatal("expected input/output Jacobians in matrix data")
+	}
+	if !mat.Equal(md.DUdy[0], ld.DUdy) {
+		t.Fatal("dUdy differs from linearization file")
+	}
+
+	// Coupling without Jacobians returns error
+	ld, err = lin.ReadLinFile("testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md = lin.NewMatData([]*lin.LinData{ld})
+	if _, err := md.Couple(0, &lin.StateSpace{A: ld.A, B: ld.B, C: ld.C, D: ld.D}); err == nil {
+		t.Fatal("expected error coupling without Jacobians")
+	}
+}
@@ -0,0 +1,298 @@
+package lin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"math"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Methods of grouping linearization files into operating points
+const (
+	GroupByName   = "Name"   // Files with the same path before the ".N.lin" suffix
+	GroupByHeader = "Header" // Files in the same directory with the same rotor and wind speed
+)
+
+// DiscoverOptions controls how linearization files are found in a directory
+// and grouped into operating points.
+type DiscoverOptions struct {
+	Recursive bool     `json:"Recursive"` // Search subdirectories
+	Patterns  []string `json:"Patterns"`  // Glob patterns matched against file names
+	GroupBy   string   `json:"GroupBy"`   // GroupByName or GroupByHeader
+}
+
+// NewDiscoverOptions returns the default options which find linearization
+// files in the top directory and group them by name.
+func NewDiscoverOptions() DiscoverOptions {
+	return DiscoverOptions{
+		Patterns: []string{"*.lin"},
+		GroupBy:  GroupByName,
+	}
+}
+
+// AmbiguousGroup is a group of linearization files which can't be processed
+// as one operating point.
+type AmbiguousGroup struct {
+	Name   string   `json:"Name"`
+	Files  []string `json:"Files"`
+	Reason string   `json:"Reason"`
+}
+
+// Discovery contains the file groups found by Discover. Ambiguous groups
+// aren't included in Groups.
+type Discovery struct {
+	Groups    []FileGroup
+	Ambiguous []AmbiguousGroup
+}
+
+// Discover finds the linearization files in dir matching the patterns and
+// groups them into operating points. The header of each file is read to
+// check that the files in a group have the same rotor and wind speed, the
+// same number of states and unique azimuths, otherwise the group is reported
+// as ambiguous. It returns the context's cause if ctx is canceled.
+func Discover(ctx context.Context, dir string, opts DiscoverOptions) (*Discovery, error) {
+
+	// Check options
+	if len(opts.Patterns) == 0 {
+		return nil, fmt.Errorf("no file name patterns specified")
+	}
+	for _, pattern := range opts.Patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid file name pattern '%s': %w", pattern, err)
+		}
+	}
+	if opts.GroupBy != GroupByName && opts.GroupBy != GroupByHeader {
+		return nil, fmt.Errorf("invalid grouping '%s'", opts.GroupBy)
+	}
+
+	// Find files matching patterns
+	paths, err := findFiles(ctx, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no linearization files found")
+	}
+
+	// Read header of each file, files in tar.gz archives are read in one pass
+	// over each archive
+	headers := map[string]*LinData{}
+	archives, others := splitTarGzFiles(paths)
+	for _, archivePath := range slices.Sorted(maps.Keys(archives)) {
+		err := readTarGzFiles(ctx, archivePath, paths, archives[archivePath], func(i int, r io.Reader) error {
+			ld, err := readLinHeader(r, paths[i])
+			headers[paths[i]] = ld
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, i := range others {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		if headers[paths[i]], err = ReadLinHeader(paths[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Group files
+	var groups []FileGroup
+	switch opts.GroupBy {
+	case GroupByName:
+		groups = GroupFilesByName(paths)
+	case GroupByHeader:
+		groups = groupFilesByHeader(paths, headers)
+	}
+
+	// Separate ambiguous groups
+	d := &Discovery{}
+	for _, fg := range groups {
+		if reason := fg.ambiguity(headers); reason != "" {
+			d.Ambiguous = append(d.Ambiguous, AmbiguousGroup{Name: fg.Name, Files: fg.Files, Reason: reason})
+		} else {
+			d.Groups = append(d.Groups, fg)
+		}
+	}
+
+	return d, nil
+}
+
+// findFiles returns the sorted paths of files in dir, and its subdirectories
+// if opts.Recursive is true, whose names match any of the patterns. Files in
+// archives are included, see OpenLinFile.
+func findFiles(ctx context.Context, dir string, opts DiscoverOptions) ([]string, error) {
+
+	paths := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if d.IsDir() {
+			if path != dir && !opts.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Search files in archive as if it were a directory
+		if isArchive(d.Name()) {
+			members, err := listArchive(path)
+			if err != nil {
+				return err
+			}
+			for _, member := range members {
+				if strings.Contains(member, "/") && !opts.Recursive {
+					continue
+				}
+				if matchPatterns(opts.Patterns, pathpkg.Base(member)) {
+					paths = append(paths, filepath.Join(path, filepath.FromSlash(member)))
+				}
+			}
+			return nil
+		}
+
+		if matchPatterns(opts.Patterns, d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error searching '%s': %w", dir, err)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// matchPatterns returns true if the file name, or the name without a ".gz"
+// extension, matches any of the patterns.
+func matchPatterns(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		for _, n := range []string{name, strings.TrimSuffix(name, ".gz")} {
+			if ok, _ := filepath.Match(pattern, n); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// linSuffixRe matches the ".N.lin" suffix of linearization file names
+var linSuffixRe = regexp.MustCompile(`(\.\d+)?\.lin(\.gz)?$`)
+
+// linRootName returns the file path without the ".N.lin" or ".lin" suffix,
+// including a trailing ".gz".
+func linRootName(path string) string {
+	return linSuffixRe.ReplaceAllString(path, "")
+}
+
+// GroupFilesByName groups the linearization files by their path without the
+// ".N.lin" suffix and returns the groups sorted by name.
+func GroupFilesByName(paths []string) []FileGroup {
+
+	groupMap := map[string]*FileGroup{}
+	for _, path := range paths {
+		name := linRootName(path)
+		fg, ok := groupMap[name]
+		if !ok {
+			fg = &FileGroup{Name: name}
+			groupMap[name] = fg
+		}
+		fg.Files = append(fg.Files, path)
+	}
+
+	groups := make([]FileGroup, 0, len(groupMap))
+	for _, fg := range groupMap {
+		groups = append(groups, *fg)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+
+	return groups
+}
+
+// groupFilesByHeader groups the linearization files which are in the same
+// directory and have the same rotor and wind speed in their headers. Paths
+// must be sorted. Each group is named after the root name of its first file,
+// or the file name without extension if another group has that name.
+func groupFilesByHeader(paths []string, headers map[string]*LinData) []FileGroup {
+
+	// Add each file to the group in the same directory whose first file has
+	// the same speeds, or create a new group
+	groupPtrs := []*FileGroup{}
+	for _, path := range paths {
+		ld := headers[path]
+		var group *FileGroup
+		for _, fg := range groupPtrs {
+			first := headers[fg.Files[0]]
+			if filepath.Dir(fg.Files[0]) == filepath.Dir(path) &&
+				closeTo(ld.RotorSpeed, first.RotorSpeed) && closeTo(ld.WindSpeed, first.WindSpeed) {
+				group = fg
+				break
+			}
+		}
+		if group == nil {
+			group = &FileGroup{}
+			groupPtrs = append(groupPtrs, group)
+		}
+		group.Files = append(group.Files, path)
+	}
+
+	// Name groups in order of first file
+	groups := make([]FileGroup, 0, len(groupPtrs))
+	names := map[string]bool{}
+	for _, fg := range groupPtrs {
+		fg.Name = linRootName(fg.Files[0])
+		if names[fg.Name] {
+			fg.Name = strings.TrimSuffix(strings.TrimSuffix(fg.Files[0], ".gz"), ".lin")
+		}
+		names[fg.Name] = true
+		groups = append(groups, *fg)
+	}
+
+	return groups
+}
+
+// ambiguity returns the reason the files in the group can't be processed as
+// one operating point, or an empty string if they can.
+func (fg *FileGroup) ambiguity(headers map[string]*LinData) string {
+	lds := make([]*LinData, len(fg.Files))
+	names := make([]string, len(fg.Files))
+	for i, path := range fg.Files {
+		lds[i], names[i] = headers[path], filepath.Base(path)
+	}
+	if err := validateHeaders(lds, names); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// azimuthTol is the difference in radians below which azimuths are the same
+const azimuthTol = 1e-3
+
+// sameAzimuth returns true if the angles in radians are within azimuthTol,
+// accounting for wrapping at 2π.
+func sameAzimuth(a, b float64) bool {
+	d := math.Mod(math.Abs(a-b), 2*math.Pi)
+	return min(d, 2*math.Pi-d) < azimuthTol
+}
+
+// closeTo returns true if the speeds are equal within a relative tolerance,
+// so speeds which differ by numerical noise are considered the same.
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-3*max(1, math.Abs(a), math.Abs(b))
+}
@@ -0,0 +1,112 @@
+package lin_test
+
+import (
+	"acdc/lin"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// writeLinFile writes a copy of a test linearization file to path with the
+// rotor speed and azimuth replaced in the header.
+func writeLinFile(t *testing.T, path string, rotSpeed, azimuth float64) {
+	bs, err := os.ReadFile("testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs = regexp.MustCompile(`Rotor Speed:\s+\S+`).ReplaceAll(bs, fmt.Appendf(nil, "Rotor Speed: %.4f", rotSpeed))
+	bs = regexp.MustCompile(`Azimuth:\s+\S+`).ReplaceAll(bs, fmt.Appendf(nil, "Azimuth: %.4f", azimuth))
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, bs, 0777); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+
+	// Create directory with files grouped by name, nested files, files from
+	// a workflow with one file per name, and files with duplicate azimuths
+	dir := t.TempDir()
+	writeLinFile(t, filepath.Join(dir, "op1.1.lin"), 1.0, 0)
+	writeLinFile(t, filepath.Join(dir, "op1.2.lin"), 1.0, 2.0944)
+	writeLinFile(t, filepath.Join(dir, "nested", "op2.1.lin"), 1.2, 0)
+	writeLinFile(t, filepath.Join(dir, "pyfast", "case_0.lin"), 0.8, 0)
+	writeLinFile(t, filepath.Join(dir, "pyfast", "case_1.lin"), 0.8, 2.0944)
+	writeLinFile(t, filepath.Join(dir, "pyfast", "case_2.lin"), 1.0, 0)
+	writeLinFile(t, filepath.Join(dir, "dup", "dup.1.lin"), 1.0, 0)
+	writeLinFile(t, filepath.Join(dir, "dup", "dup.2.lin"), 1.0, 0)
+
+	groupNames := func(d *lin.Discovery) map[string]int {
+		names := map[string]int{}
+		for _, fg := range d.Groups {
+			rel, _ := filepath.Rel(dir, fg.Name)
+			names[filepath.ToSlash(rel)] = len(fg.Files)
+		}
+		return names
+	}
+
+	// Only top directory is searched by default
+	d, err := lin.Discover(context.Background(), dir, lin.NewDiscoverOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := groupNames(d), map[string]int{"op1": 2}; !reflect.DeepEqual(act, exp) {
+		t.Fatalf("groups = %v, expected %v", act, exp)
+	}
+
+	// Recursive search finds nested files, each pyFAST file is its own group
+	// and files with duplicate azimuths are ambiguous
+	opts := lin.NewDiscoverOptions()
+	opts.Recursive = true
+	d, err = lin.Discover(context.Background(), dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]int{"op1": 2, "nested/op2": 1, "pyfast/case_0": 1, "pyfast/case_1": 1, "pyfast/case_2": 1}
+	if act := groupNames(d); !reflect.DeepEqual(act, exp) {
+		t.Fatalf("groups = %v, expected %v", act, exp)
+	}
+	if len(d.Ambiguous) != 1 || d.Ambiguous[0].Name != filepath.Join(dir, "dup", "dup") {
+		t.Fatalf("ambiguous = %+v, expected dup group", d.Ambiguous)
+	}
+	if reason := d.Ambiguous[0].Reason; !strings.Contains(reason, "azimuth") || !strings.Contains(reason, "'dup.2.lin'") {
+		t.Fatalf("ambiguous reason = %q, expected duplicate azimuth of 'dup.2.lin'", reason)
+	}
+
+	// Grouping by header combines pyFAST files with the same rotor speed
+	opts.GroupBy = lin.GroupByHeader
+	opts.Patterns = []string{"case_*.lin"}
+	d, err = lin.Discover(context.Background(), dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := groupNames(d), map[string]int{"pyfast/case_0": 2, "pyfast/case_2": 1}; !reflect.DeepEqual(act, exp) {
+		t.Fatalf("groups = %v, expected %v", act, exp)
+	}
+
+	// Patterns without matches and invalid patterns return errors
+	opts.Patterns = []string{"*.txt"}
+	if _, err := lin.Discover(context.Background(), dir, opts); err == nil {
+		t.Fatal("expected error when no files match")
+	}
+	opts.Patterns = []string{"["}
+	if _, err := lin.Discover(context.Background(), dir, opts); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+
+	// Canceled discovery returns the cancellation cause
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cause := fmt.Errorf("discovery canceled")
+	cancel(cause)
+	if _, err := lin.Discover(ctx, dir, lin.NewDiscoverOptions()); !errors.Is(err, cause) {
+		t.Fatalf("err = %v, expected %v", err, cause)
+	}
+}
@@ -2,11 +2,18 @@ package lin
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
 )
 
 type FileGroup struct {
@@ -22,111 +29,112 @@ type LinOP struct {
 	Modes         Modes    `json:"-"`
 }
 
-// ProcessFiles takes a slice of linearization file paths, groups them by
-// operating point and performs MBC/Eigenanalysis for each OP. It returns
-// a slice of operating point linearization results.
-func ProcessFiles(LinFilePaths []string) ([]LinOP, error) {
+// ProcessOptions contains options for processing linearization files.
+type ProcessOptions struct {
+	NumCPUs  int                   // Maximum number of files read or OPs analyzed concurrently
+	UseCache bool                  // Reuse results of OPs whose files haven't changed
+	Progress func(ProcessProgress) // Called as files are read and OPs are analyzed, may be nil
+}
 
-	// Group linearization files by operating point using the file name
-	opFilesMap := map[string]*FileGroup{}
-	for _, filePath := range LinFilePaths {
+// NewProcessOptions returns the default processing options which use all
+// available CPUs and cached results.
+func NewProcessOptions() ProcessOptions {
+	return ProcessOptions{NumCPUs: runtime.NumCPU(), UseCache: true}
+}
 
-		// Split file path by '.'
-		tmp := strings.Split(filePath, ".")
+// Processing stages reported in ProcessProgress
+const (
+	StageReading   = "Reading"   // Linearization file was read
+	StageAnalyzing = "Analyzing" // MBC and eigenanalysis started for an OP
+	StageAnalyzed  = "Analyzed"  // MBC and eigenanalysis finished for an OP
+	StageCached    = "Cached"    // Results for an OP were loaded from cache
+	StageComplete  = "Complete"  // All OPs have been processed
+)
 
-		// Get the root name of the file without extension or lin numbers
-		rootName := strings.Join(tmp[:len(tmp)-2], ".")
+// ProcessProgress describes the progress of processing after a file has been
+// read or an operating point has been analyzed.
+type ProcessProgress struct {
+	Stage        string // Stage of the file or OP that triggered the update
+	RootPath     string // Root path of the operating point being processed
+	NumFiles     int    // Total number of linearization files
+	NumFilesRead int    // Number of linearization files read so far
+	NumOPs       int    // Total number of operating points
+	NumOPsDone   int    // Number of operating points analyzed so far
+}
 
-		// Get file group for this operating point, create it if it doesn't exist
-		opFiles, ok := opFilesMap[rootName]
-		if !ok {
-			opFiles = &FileGroup{Name: rootName}
-			opFilesMap[rootName] = opFiles
-		}
+// ProcessFiles takes a slice of linearization file paths, groups them by
+// operating point using the file names and performs MBC/Eigenanalysis for
+// each OP. See ProcessFileGroups for details.
+func ProcessFiles(ctx context.Context, LinFilePaths []string, opts ProcessOptions) ([]LinOP, error) {
+	return ProcessFileGroups(ctx, GroupFilesByName(LinFilePaths), opts)
+}
 
-		// Append current file path to slice of files
-		opFiles.Files = append(opFiles.Files, filePath)
-	}
+// ProcessFileGroups performs MBC/Eigenanalysis for each operating point file
+// group. Files are read and OPs are analyzed concurrently, limited by
+// opts.NumCPUs. It returns a slice of operating point linearization results
+// sorted by wind speed or rotor speed, OPs with equal speeds are sorted by
+// name. Processing stops early if ctx is canceled.
+func ProcessFileGroups(ctx context.Context, groups []FileGroup, opts ProcessOptions) ([]LinOP, error) {
 
-	// Initialize flag that wind was present to false
-	hasWind := false
+	// Limit concurrency to at least one
+	numCPUs := max(1, opts.NumCPUs)
 
-	results := []LinOP{}
-	for _, fg := range opFilesMap {
-		// Read all linearization files in group
-		linFileData := make([]*LinData, len(fg.Files))
-		var err error
-		for i, linFilePath := range fg.Files {
-			linFileData[i], err = ReadLinFile(linFilePath)
-			if err != nil {
-				return nil, fmt.Errorf("error reading '%s': %w", linFilePath, err)
-			}
-		}
-
-		// Extract matrix data from linearization file data
-		matData := NewMatData(linFileData)
-
-		// Perform multi-blade coordinate transform
-		mbc, err := matData.MBC3()
-		if err != nil {
-			return nil, err
-		}
+	// Sort file groups by name so processing order doesn't depend on input order
+	fileGroups := make([]*FileGroup, len(groups))
+	numFiles := 0
+	for i := range groups {
+		fileGroups[i] = &groups[i]
+		numFiles += len(groups[i].Files)
+	}
+	sort.SliceStable(fileGroups, func(i, j int) bool {
+		return fileGroups[i].Name < fileGroups[j].Name
+	})
 
-		// Perform Eigenanalysis to get modes
-		modes, err := mbc.EigenAnalysis()
-		if err != nil {
-			return nil, err
-		}
+	// Semaphore shared by all OPs to limit the number of concurrent file reads
+	readSem := make(chan struct{}, numCPUs)
 
-		// Write MBC data to file
-		bs, err := json.MarshalIndent(mbc, "", "\t")
-		if err != nil {
-			return nil, err
-		}
-		err = os.WriteFile(fg.Name+"_mbc.json", bs, 0777)
-		if err != nil {
-			return nil, err
-		}
-
-		// Write Eigen analysis mode results data to file
-		w := &bytes.Buffer{}
-		modes.ToCSV(w)
-		err = os.WriteFile(fg.Name+"_modes.csv", w.Bytes(), 0777)
-		if err != nil {
-			return nil, err
-		}
+	// Create progress reporter to serialize updates from goroutines
+	pr := &progressReporter{
+		callback: opts.Progress,
+		progress: ProcessProgress{NumFiles: numFiles, NumOPs: len(fileGroups)},
+	}
 
-		// Determine if any AeroDyn states are in eigenanalysis
-		hasAeroStates := false
-		for _, dof := range mbc.DOFsEigen {
-			if strings.HasPrefix(dof, "AD") {
-				hasAeroStates = true
-				break
+	// Process OPs concurrently, each result is stored at the group's index.
+	// The group context is canceled on the first error.
+	results := make([]LinOP, len(fileGroups))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(numCPUs)
+	for i, fg := range fileGroups {
+		g.Go(func() error {
+			linOP, err := processFileGroup(gctx, fg, opts, readSem, pr)
+			if err != nil {
+				return err
 			}
-		}
+			results[i] = *linOP
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-		// If wind speed is nonzero, set flag to true
-		if mbc.WindSpeed > 0 {
+	// Determine if wind was present in any OP
+	hasWind := false
+	for _, r := range results {
+		if r.MBC.WindSpeed > 0 {
 			hasWind = true
+			break
 		}
-
-		// Return MBC and eigen analysis results
-		results = append(results, LinOP{
-			RootPath:      fg.Name,
-			FilePaths:     fg.Files,
-			HasAeroStates: hasAeroStates,
-			MBC:           mbc,
-			Modes:         modes,
-		})
 	}
 
-	// Sort results by wind speed or rotor speed
+	// Sort results by wind speed or rotor speed, stable so OPs with equal
+	// speeds remain in order of name
 	if hasWind {
-		sort.Slice(results, func(i, j int) bool {
+		sort.SliceStable(results, func(i, j int) bool {
 			return results[i].MBC.WindSpeed < results[j].MBC.WindSpeed
 		})
 	} else {
-		sort.Slice(results, func(i, j int) bool {
+		sort.SliceStable(results, func(i, j int) bool {
 			return results[i].MBC.RotSpeed < results[j].MBC.RotSpeed
 		})
 	}
@@ -138,5 +146,199 @@ func ProcessFiles(LinFilePaths []string) ([]LinOP, error) {
 		}
 	}
 
+	// Report that processing is complete
+	pr.update(StageComplete, "", 0, 0)
+
 	return results, nil
 }
+
+// progressReporter serializes progress updates from concurrent goroutines
+// and accumulates the number of files read and OPs analyzed.
+type progressReporter struct {
+	mutex    sync.Mutex
+	callback func(ProcessProgress)
+	progress ProcessProgress
+}
+
+// update adds the number of files read and OPs analyzed to the progress and
+// calls the callback, if any, with the given stage and OP root path.
+func (pr *progressReporter) update(stage, rootPath string, filesRead, opsDone int) {
+	if pr.callback == nil {
+		return
+	}
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+	pr.progress.Stage = stage
+	pr.progress.RootPath = rootPath
+	pr.progress.NumFilesRead += filesRead
+	pr.progress.NumOPsDone += opsDone
+	pr.callback(pr.progress)
+}
+
+// processFileGroup reads the linearization files in the group concurrently,
+// using readSem to limit the number of concurrent reads, then performs MBC
+// and Eigenanalysis. If opts.UseCache is true, the results are cached next to
+// the files and loaded from the cache if the files and options haven't
+// changed. Files inside archives aren't cached. Progress is reported to pr
+// and it returns the context's cause if ctx is canceled.
+func processFileGroup(ctx context.Context, fg *FileGroup, opts ProcessOptions, readSem chan struct{}, pr *progressReporter) (*LinOP, error) {
+
+	// If using cache, return cached results if the file contents match
+	useCache := opts.UseCache && !fg.inArchive()
+	cacheKey := ""
+	if useCache {
+		var err error
+		if cacheKey, err = fg.cacheKey(opts); err != nil {
+			return nil, err
+		}
+		if linOP := fg.loadCached(cacheKey); linOP != nil {
+			pr.update(StageCached, fg.Name, len(fg.Files), 1)
+			return linOP, nil
+		}
+	}
+
+	// Read all linearization files in group. Files in tar.gz archives are read
+	// in one pass over each archive, other files are read concurrently.
+	linFileData := make([]*LinData, len(fg.Files))
+	archives, others := splitTarGzFiles(fg.Files)
+	g := errgroup.Group{}
+	for archivePath, indices := range archives {
+		g.Go(func() error {
+
+			// Wait for a read slot or cancellation
+			select {
+			case readSem <- struct{}{}:
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+			defer func() { <-readSem }()
+
+			return readTarGzFiles(ctx, archivePath, fg.Files, indices, func(i int, r io.Reader) error {
+				ld, err := ReadLin(r, linFileID(fg.Files[i]))
+				if err != nil {
+					return fmt.Errorf("error reading '%s': %w", fg.Files[i], err)
+				}
+				linFileData[i] = ld
+				pr.update(StageReading, fg.Name, 1, 0)
+				return nil
+			})
+		})
+	}
+	for _, i := range others {
+		linFilePath := fg.Files[i]
+		g.Go(func() error {
+
+			// Wait for a read slot or cancellation
+			select {
+			case readSem <- struct{}{}:
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+			defer func() { <-readSem }()
+
+			ld, err := ReadLinFile(linFilePath)
+			if err != nil {
+				return fmt.Errorf("error reading '%s': %w", linFilePath, err)
+			}
+			linFileData[i] = ld
+			pr.update(StageReading, fg.Name, 1, 0)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Return if canceled while files were being read
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	pr.update(StageAnalyzing, fg.Name, 0, 0)
+
+	// Check that files can be combined into one operating point
+	if err := ValidateLinData(linFileData, fg.Files); err != nil {
+		return nil, fmt.Errorf("error in operating point '%s': %w", fg.Name, err)
+	}
+
+	// Discrete and constraint states are parsed but only continuous states
+	// are transformed and analyzed, log that the others are excluded
+	if ld := linFileData[0]; ld.Num_xd > 0 || ld.Num_z > 0 {
+		log.Printf("operating point '%s' has %d discrete and %d constraint states, "+
+			"which are excluded from the MBC transform and eigenanalysis", fg.Name, ld.Num_xd, ld.Num_z)
+	}
+
+	// Extract matrix data from linearization file data
+	matData := NewMatData(linFileData)
+
+	// Perform multi-blade coordinate transform
+	mbc, err := matData.MBC3()
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform Eigenanalysis to get modes
+	modes, err := mbc.EigenAnalysis()
+	if err != nil {
+		return nil, err
+	}
+
+	linOP := &LinOP{
+		RootPath:      fg.Name,
+		FilePaths:     fg.Files,
+		HasAeroStates: mbc.hasAeroStates(),
+		MBC:           mbc,
+		Modes:         modes,
+	}
+
+	// Write cache file so unchanged files aren't processed again, the results
+	// are still valid if the cache can't be written so only log the error
+	if useCache {
+		if err := fg.writeCache(cacheKey, linOP); err != nil {
+			log.Printf("error caching operating point '%s': %s", fg.Name, err)
+		}
+	}
+
+	pr.update(StageAnalyzed, fg.Name, 0, 1)
+
+	return linOP, nil
+}
+
+// hasAeroStates returns true if any AeroDyn states are in the eigenanalysis.
+func (mbc *MBC) hasAeroStates() bool {
+	for _, dof := range mbc.DOFsEigen {
+		if strings.HasPrefix(dof, "AD") {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadOPResults reads the MBC data and modes of the operating point with the
+// given root path from the JSON and CSV files written by previous versions,
+// before results were stored in a results container file.
+func ReadOPResults(rootPath string) (*MBC, Modes, error) {
+
+	// Load MBC data
+	mbcFile := rootPath + "_mbc.json"
+	bs, err := os.ReadFile(mbcFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	mbc := &MBC{}
+	if err := json.Unmarshal(bs, mbc); err != nil {
+		return nil, nil, fmt.Errorf("error parsing '%s': %w", mbcFile, err)
+	}
+
+	// Load Eigen analysis mode results
+	modesFile := rootPath + "_modes.csv"
+	bs, err = os.ReadFile(modesFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	modes, err := ReadModesCSV(bytes.NewReader(bs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing '%s': %w", modesFile, err)
+	}
+
+	return mbc, modes, nil
+}
@@ -2,8 +2,17 @@ package lin_test
 
 import (
 	"acdc/lin"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"gonum.org/v1/gonum/mat"
 )
 
 func TestLoadResults(t *testing.T) {
@@ -18,8 +27,251 @@ func TestLoadResults(t *testing.T) {
 		t.Fatal("no lin files found")
 	}
 
-	_, err = lin.ProcessFiles(LinFiles)
+	// Process without cache so cache files aren't written to testdata
+	opts := lin.NewProcessOptions()
+	opts.UseCache = false
+	_, err = lin.ProcessFiles(context.Background(), LinFiles, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessFilesParallel(t *testing.T) {
+
+	LinFiles, err := filepath.Glob(filepath.Join("testdata", "*.lin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Process files serially
+	serial, err := lin.ProcessFiles(context.Background(), LinFiles, lin.ProcessOptions{NumCPUs: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Process files in parallel and record progress callbacks
+	progress := []lin.ProcessProgress{}
+	parallel, err := lin.ProcessFiles(context.Background(), LinFiles, lin.ProcessOptions{
+		NumCPUs:  4,
+		Progress: func(p lin.ProcessProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check that every file read and OP analysis was reported and the final
+	// update reports completion
+	numRead, numAnalyzed := 0, 0
+	for _, p := range progress {
+		switch p.Stage {
+		case lin.StageReading:
+			numRead++
+		case lin.StageAnalyzed:
+			numAnalyzed++
+		}
+	}
+	if numRead != len(LinFiles) || numAnalyzed != len(serial) {
+		t.Fatalf("got %d read and %d analyzed updates, expected %d and %d",
+			numRead, numAnalyzed, len(LinFiles), len(serial))
+	}
+	last := progress[len(progress)-1]
+	if exp := (lin.ProcessProgress{Stage: lin.StageComplete, NumFiles: len(LinFiles),
+		NumFilesRead: len(LinFiles), NumOPs: len(serial), NumOPsDone: len(serial)}); last != exp {
+		t.Fatalf("last progress = %+v, expected %+v", last, exp)
+	}
+
+	// Check that results are in the same order with the same modes
+	if len(parallel) != len(serial) {
+		t.Fatalf("got %d OPs, expected %d", len(parallel), len(serial))
+	}
+	for i := range serial {
+		if parallel[i].RootPath != serial[i].RootPath {
+			t.Fatalf("OP %d = %s, expected %s", i, parallel[i].RootPath, serial[i].RootPath)
+		}
+		if len(parallel[i].Modes) != len(serial[i].Modes) {
+			t.Fatalf("OP %d has %d modes, expected %d", i, len(parallel[i].Modes), len(serial[i].Modes))
+		}
+		for j := range serial[i].Modes {
+			if parallel[i].Modes[j].NaturalFreqHz != serial[i].Modes[j].NaturalFreqHz {
+				t.Fatalf("OP %d mode %d frequency differs", i, j)
+			}
+		}
+	}
+}
+
+func TestProcessFilesCancel(t *testing.T) {
+
+	LinFiles, err := filepath.Glob(filepath.Join("testdata", "*.lin"))
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	// Cancel processing after the first file is read
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	errCanceled := errors.New("canceled")
+	_, err = lin.ProcessFiles(ctx, LinFiles, lin.ProcessOptions{
+		NumCPUs:  1,
+		Progress: func(p lin.ProcessProgress) { cancel(errCanceled) },
+	})
+	if !errors.Is(err, errCanceled) {
+		t.Fatalf("error = %v, expected %v", err, errCanceled)
+	}
+}
+
+func TestProcessFilesCache(t *testing.T) {
+
+	// Copy two operating points into a temporary directory
+	dir := t.TempDir()
+	copyLin := func(name string) string {
+		bs, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, bs, 0777); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	LinFiles := []string{
+		copyLin("StC_test_OC4Semi_Linear_Nac.1.lin"),
+		copyLin("StC_test_OC4Semi_Linear_Tow.1.lin"),
+	}
+
+	// Process files and count the stage of each OP
+	process := func() ([]lin.LinOP, map[string]int) {
+		stages := map[string]int{}
+		opts := lin.NewProcessOptions()
+		opts.Progress = func(p lin.ProcessProgress) { stages[p.Stage]++ }
+		linOPs, err := lin.ProcessFiles(context.Background(), LinFiles, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return linOPs, stages
+	}
+
+	// First run analyzes all OPs
+	first, stages := process()
+	if stages[lin.StageAnalyzed] != 2 || stages[lin.StageCached] != 0 {
+		t.Fatalf("first run stages = %v, expected 2 analyzed", stages)
+	}
+
+	// Second run loads all OPs from cache with the same results
+	second, stages := process()
+	if stages[lin.StageAnalyzed] != 0 || stages[lin.StageCached] != 2 {
+		t.Fatalf("second run stages = %v, expected 2 cached", stages)
+	}
+	for i := range first {
+		if !reflect.DeepEqual(first[i].Modes, second[i].Modes) {
+			t.Fatalf("cached modes of OP %d differ", i)
+		}
+		if first[i].HasAeroStates != second[i].HasAeroStates {
+			t.Fatalf("cached HasAeroStates of OP %d differs", i)
+		}
+	}
+
+	// Adding an OP only analyzes the new OP
+	LinFiles = append(LinFiles, copyLin("WP_Stationary_Linear.1.lin"))
+	_, stages = process()
+	if stages[lin.StageAnalyzed] != 1 || stages[lin.StageCached] != 2 {
+		t.Fatalf("third run stages = %v, expected 1 analyzed and 2 cached", stages)
+	}
+
+	// Changing a file reanalyzes its OP
+	bs, err := os.ReadFile(LinFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(LinFiles[0], append(bs, '\n'), 0777); err != nil {
+		t.Fatal(err)
+	}
+	_, stages = process()
+	if stages[lin.StageAnalyzed] != 1 || stages[lin.StageCached] != 2 {
+		t.Fatalf("fourth run stages = %v, expected 1 analyzed and 2 cached", stages)
+	}
+
+	// Failing to write the cache doesn't fail processing or leave temporary files
+	LinFiles = append(LinFiles, copyLin("Ideal_Beam_Fixed_Free_Linear.1.lin"))
+	if err := os.Mkdir(filepath.Join(dir, "Ideal_Beam_Fixed_Free_Linear_cache.bin"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	_, stages = process()
+	if stages[lin.StageAnalyzed] != 1 || stages[lin.StageCached] != 3 {
+		t.Fatalf("fifth run stages = %v, expected 1 analyzed and 3 cached", stages)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".*.tmp*")); len(matches) != 0 {
+		t.Fatalf("temporary files not removed: %v", matches)
+	}
+}
+
+func TestValidateLinData(t *testing.T) {
+
+	// Write three azimuth files of one operating point
+	dir := t.TempDir()
+	read := func() *lin.LinData {
+		ld, err := lin.ReadLinFile("testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ld
+	}
+	lds := []*lin.LinData{read(), read(), read()}
+	paths := make([]string, len(lds))
+	for i, ld := range lds {
+		ld.Azimuth = float64(i) * 2 * math.Pi / 3
+		paths[i] = filepath.Join(dir, fmt.Sprintf("op.%d.lin", i+1))
+		if err := lin.WriteLinFile(paths[i], ld); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Consistent files are processed
+	opts := lin.NewProcessOptions()
+	opts.UseCache = false
+	if _, err := lin.ProcessFiles(context.Background(), paths, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each inconsistency returns an error naming the third file
+	cases := []struct {
+		name   string
+		modify func(ld *lin.LinData)
+	}{
+		{"rotor speed", func(ld *lin.LinData) { ld.RotorSpeed *= 1.1 }},
+		{"wind speed", func(ld *lin.LinData) { ld.WindSpeed = 10 }},
+		{"state order", func(ld *lin.LinData) { ld.OP_x[0], ld.OP_x[1] = ld.OP_x[1], ld.OP_x[0] }},
+		{"azimuth", func(ld *lin.LinData) { ld.Azimuth = 2*math.Pi + 1e-5 }},
+		{"jacobians", func(ld *lin.LinData) {
+			ld.DUdu = mat.NewDense(ld.Num_u, ld.Num_u, nil)
+			ld.DUdy = mat.NewDense(ld.Num_u, ld.Num_y, nil)
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ld := read()
+			ld.Azimuth = lds[2].Azimuth
+			c.modify(ld)
+			err := lin.ValidateLinData([]*lin.LinData{lds[0], lds[1], ld}, paths)
+			if err == nil || !strings.Contains(err.Error(), paths[2]) {
+				t.Fatalf("error = %v, expected error naming '%s'", err, paths[2])
+			}
+		})
+	}
+
+	// Different number of states is reported when processing
+	ld := read()
+	ld.Azimuth = lds[2].Azimuth
+	ld.Num_x--
+	ld.OP_x, ld.OP_xdot = ld.OP_x[:ld.Num_x], ld.OP_xdot[:ld.Num_x]
+	ld.A = mat.DenseCopyOf(ld.A.Slice(0, ld.Num_x, 0, ld.Num_x))
+	ld.B = mat.DenseCopyOf(ld.B.Slice(0, ld.Num_x, 0, ld.Num_u))
+	ld.C = mat.DenseCopyOf(ld.C.Slice(0, ld.Num_y, 0, ld.Num_x))
+	if err := lin.WriteLinFile(paths[2], ld); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lin.ProcessFiles(context.Background(), paths, opts); err == nil ||
+		!strings.Contains(err.Error(), paths[2]) {
+		t.Fatalf("error = %v, expected error naming '%s'", err, paths[2])
+	}
 }
@@ -3,8 +3,8 @@ package lin
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"math"
-	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -33,7 +33,7 @@ type LinData struct {
 	OP_xd, OP_z   OPSlice
 
 	A, B, C, D *mat.Dense
-	dUdu, dUdy *mat.Dense
+	DUdu, DUdy *mat.Dense // Glue-code input/output Jacobians, nil unless LinOutJac is enabled
 }
 
 func NewLinData(ID int) *LinData {
@@ -136,29 +136,43 @@ func (ops OPSlice) Sort() OPSlice {
 	return opsSorted
 }
 
-// ReadLinFile reads the linearization file and parses the data. File name
-// must end in ".N.lin" where N is a number
+// maxLinLineSize is the longest line in a linearization file which can be
+// read. Matrix values are written with about 25 characters, so this allows
+// matrices with more than two million columns.
+const maxLinLineSize = 64 * 1024 * 1024
+
+// newLinScanner returns a scanner which reads the lines of a linearization
+// file from r, allowing lines longer than the default limit as each matrix
+// row is written on one line.
+func newLinScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLinLineSize)
+	return scanner
+}
+
+// ReadLinFile reads the linearization file and parses the data. If the file
+// name ends in ".N.lin", where N is a number, N is used as the file ID. The
+// path may be a gzip compressed file or a file inside an archive, see
+// OpenLinFile.
 func ReadLinFile(filePath string) (*LinData, error) {
 
 	// Open linearization file
-	linFile, err := os.Open(filePath)
+	linFile, err := OpenLinFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer linFile.Close()
 
-	// Get linearization file ID
-	tmp := strings.Split(filepath.Base(filePath), ".")
-	if len(tmp) < 3 {
-		return nil, fmt.Errorf("invalid lin file name, must end in '.N.lin'")
-	}
-	ID, err := strconv.Atoi(tmp[len(tmp)-2])
-	if err != nil {
-		return nil, fmt.Errorf("invalid lin file name, must end in '.N.lin'")
-	}
+	return ReadLin(linFile, linFileID(filePath))
+}
+
+// ReadLin reads linearization data from r and assigns it the given ID.
+func ReadLin(r io.Reader, ID int) (*LinData, error) {
+
+	var err error
 
 	// Create scanner to read linearization file
-	scanner := bufio.NewScanner(linFile)
+	scanner := newLinScanner(r)
 
 	// Initialize linearization data structure
 	ld := &LinData{ID: ID}
@@ -167,57 +181,8 @@ func ReadLinFile(filePath string) (*LinData, error) {
 	// Header
 	//--------------------------------------------------------------------------
 
-	for scanner.Scan() {
-
-		// Get line without leading/trailing whitespace, skip if empty
-		line := strings.TrimSpace(scanner.Text())
-		if len(line) == 0 {
-			continue
-		}
-
-		// Split line into fields
-		fields := strings.Fields(line)
-
-		if strings.HasPrefix(line, "Simulation time") {
-			if ld.SimTime, err = strconv.ParseFloat(fields[2], 64); err != nil {
-				return nil, fmt.Errorf("error parsing Simulation time: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Rotor Speed") {
-			if ld.RotorSpeed, err = strconv.ParseFloat(fields[2], 64); err != nil {
-				return nil, fmt.Errorf("error parsing Rotor Speed: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Azimuth") {
-			if ld.Azimuth, err = strconv.ParseFloat(fields[1], 64); err != nil {
-				return nil, fmt.Errorf("error parsing Azimuth: %w", err)
-			}
-			ld.Azimuth = math.Mod(ld.Azimuth, 2*math.Pi)
-		} else if strings.HasPrefix(line, "Wind Speed") {
-			if ld.WindSpeed, err = strconv.ParseFloat(fields[2], 64); err != nil {
-				return nil, fmt.Errorf("error parsing Wind Speed: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Number of continuous states") {
-			if ld.Num_x, err = strconv.Atoi(fields[4]); err != nil {
-				return nil, fmt.Errorf("error parsing Number of continuous states: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Number of discrete states") {
-			if ld.Num_xd, err = strconv.Atoi(fields[4]); err != nil {
-				return nil, fmt.Errorf("error parsing Number of discrete states: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Number of constraint states") {
-			if ld.Num_z, err = strconv.Atoi(fields[4]); err != nil {
-				return nil, fmt.Errorf("error parsing Number of constraint states: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Number of inputs") {
-			if ld.Num_u, err = strconv.Atoi(fields[3]); err != nil {
-				return nil, fmt.Errorf("error parsing Number of inputs: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Number of outputs") {
-			if ld.Num_y, err = strconv.Atoi(fields[3]); err != nil {
-				return nil, fmt.Errorf("error parsing Number of outputs: %w", err)
-			}
-		} else if strings.HasPrefix(line, "Jacobians included") {
-			break
-		}
+	if err := ld.readHeader(scanner); err != nil {
+		return nil, err
 	}
 
 	//--------------------------------------------------------------------------
@@ -244,8 +209,12 @@ func ReadLinFile(filePath string) (*LinData, error) {
 			defaultDeriv = 2
 			continue
 		case "Order of discrete states:":
-			currentOP = &ld.OP_x
-			defaultDeriv = 2
+			currentOP = &ld.OP_xd
+			defaultDeriv = 0
+			continue
+		case "Order of constraint states:":
+			currentOP = &ld.OP_z
+			defaultDeriv = 0
 			continue
 		case "Order of inputs:":
 			currentOP = &ld.OP_u
@@ -358,9 +327,9 @@ func ReadLinFile(filePath string) (*LinData, error) {
 			case "D:":
 				ld.D = matrix
 			case "dUdu:":
-				ld.dUdu = matrix
+				ld.DUdu = matrix
 			case "dUdy:":
-				ld.dUdy = matrix
+				ld.DUdy = matrix
 			}
 
 			continue
@@ -381,5 +350,157 @@ func ReadLinFile(filePath string) (*LinData, error) {
 		return nil, err
 	}
 
+	// Check that the data is consistent with the header
+	if err := ld.checkCounts(); err != nil {
+		return nil, err
+	}
+
+	return ld, nil
+}
+
+// checkCounts returns an error if the number of operating points in a section
+// or the size of the state matrix doesn't match the counts in the header.
+func (ld *LinData) checkCounts() error {
+
+	// Number of operating points in each section must match header,
+	// derivatives are only checked if present as older files omit them
+	type section struct {
+		name string
+		num  int
+		ops  OPSlice
+	}
+	sections := []section{
+		{"continuous states", ld.Num_x, ld.OP_x},
+		{"discrete states", ld.Num_xd, ld.OP_xd},
+		{"constraint states", ld.Num_z, ld.OP_z},
+		{"inputs", ld.Num_u, ld.OP_u},
+		{"outputs", ld.Num_y, ld.OP_y},
+	}
+	if len(ld.OP_xdot) > 0 {
+		sections = append(sections, section{"continuous state derivatives", ld.Num_x, ld.OP_xdot})
+	}
+	for _, s := range sections {
+		if len(s.ops) != s.num {
+			return fmt.Errorf("found %d %s, header specifies %d", len(s.ops), s.name, s.num)
+		}
+	}
+
+	// State matrix must only contain continuous states
+	if ld.A != nil {
+		if r, c := ld.A.Dims(); r != ld.Num_x || c != ld.Num_x {
+			return fmt.Errorf("state matrix is %dx%d, expected %dx%d continuous states",
+				r, c, ld.Num_x, ld.Num_x)
+		}
+	}
+
+	// Input/output Jacobians must match number of inputs and outputs
+	if ld.DUdu != nil {
+		if r, c := ld.DUdu.Dims(); r != ld.Num_u || c != ld.Num_u {
+			return fmt.Errorf("dUdu is %dx%d, expected %dx%d", r, c, ld.Num_u, ld.Num_u)
+		}
+	}
+	if ld.DUdy != nil {
+		if r, c := ld.DUdy.Dims(); r != ld.Num_u || c != ld.Num_y {
+			return fmt.Errorf("dUdy is %dx%d, expected %dx%d", r, c, ld.Num_u, ld.Num_y)
+		}
+	}
+
+	return nil
+}
+
+// ReadLinHeader reads the simulation information from the header of the
+// linearization file without reading the operating points or matrices.
+func ReadLinHeader(filePath string) (*LinData, error) {
+
+	linFile, err := OpenLinFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer linFile.Close()
+
+	return readLinHeader(linFile, filePath)
+}
+
+// readLinHeader reads the header of the linearization file at filePath from r.
+func readLinHeader(r io.Reader, filePath string) (*LinData, error) {
+	ld := &LinData{ID: linFileID(filePath)}
+	if err := ld.readHeader(newLinScanner(r)); err != nil {
+		return nil, fmt.Errorf("error reading '%s': %w", filePath, err)
+	}
 	return ld, nil
 }
+
+// linFileID returns N if the file name ends in ".N.lin" or ".N.lin.gz",
+// otherwise zero.
+func linFileID(filePath string) int {
+	tmp := strings.Split(strings.TrimSuffix(filepath.Base(filePath), ".gz"), ".")
+	if len(tmp) < 3 {
+		return 0
+	}
+	ID, err := strconv.Atoi(tmp[len(tmp)-2])
+	if err != nil {
+		return 0
+	}
+	return ID
+}
+
+// readHeader parses the simulation information lines from the scanner,
+// stopping after the line indicating if Jacobians are included.
+func (ld *LinData) readHeader(scanner *bufio.Scanner) error {
+
+	var err error
+	for scanner.Scan() {
+
+		// Get line without leading/trailing whitespace, skip if empty
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		// Split line into fields
+		fields := strings.Fields(line)
+
+		if strings.HasPrefix(line, "Simulation time") {
+			if ld.SimTime, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				return fmt.Errorf("error parsing Simulation time: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Rotor Speed") {
+			if ld.RotorSpeed, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				return fmt.Errorf("error parsing Rotor Speed: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Azimuth") {
+			if ld.Azimuth, err = strconv.ParseFloat(fields[1], 64); err != nil {
+				return fmt.Errorf("error parsing Azimuth: %w", err)
+			}
+			ld.Azimuth = math.Mod(ld.Azimuth, 2*math.Pi)
+		} else if strings.HasPrefix(line, "Wind Speed") {
+			if ld.WindSpeed, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				return fmt.Errorf("error parsing Wind Speed: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Number of continuous states") {
+			if ld.Num_x, err = strconv.Atoi(fields[4]); err != nil {
+				return fmt.Errorf("error parsing Number of continuous states: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Number of discrete states") {
+			if ld.Num_xd, err = strconv.Atoi(fields[4]); err != nil {
+				return fmt.Errorf("error parsing Number of discrete states: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Number of constraint states") {
+			if ld.Num_z, err = strconv.Atoi(fields[4]); err != nil {
+				return fmt.Errorf("error parsing Number of constraint states: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Number of inputs") {
+			if ld.Num_u, err = strconv.Atoi(fields[3]); err != nil {
+				return fmt.Errorf("error parsing Number of inputs: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Number of outputs") {
+			if ld.Num_y, err = strconv.Atoi(fields[3]); err != nil {
+				return fmt.Errorf("error parsing Number of outputs: %w", err)
+			}
+		} else if strings.HasPrefix(line, "Jacobians included") {
+			break
+		}
+	}
+
+	return scanner.Err()
+}
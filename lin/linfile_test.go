@@ -2,6 +2,11 @@ package lin_test
 
 import (
 	"acdc/lin"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -129,3 +134,81 @@ func TestReadLinFile(t *testing.T) {
 		t.Fatalf("ld.D.Dims() = [%v,%v], expected [%v,%v]", rAct, cAct, rExp, cExp)
 	}
 }
+
+func TestReadLinDiscreteStates(t *testing.T) {
+
+	bs, err := os.ReadFile("testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add discrete and constraint state sections before inputs
+	const sections = `Order of discrete states:
+   Row/Column Operating Point                     Rotating Frame? Derivative Order Description
+   ---------- ---------------                     --------------- ---------------- -----------
+          1    1.000E+00                                 F               0         SrvD discrete state 1
+          2    2.000E+00                                 F               0         SrvD discrete state 2
+
+Order of constraint states:
+   Row/Column Operating Point                     Rotating Frame? Derivative Order Description
+   ---------- ---------------                     --------------- ---------------- -----------
+          1    3.000E+00                                 F               0         SrvD constraint state 1
+
+Order of inputs:`
+	text := strings.Replace(string(bs), "Order of inputs:", sections, 1)
+	text = regexp.MustCompile(`Number of discrete states:\s+0`).ReplaceAllString(text, "Number of discrete states: 2")
+	text = regexp.MustCompile(`Number of constraint states:\s+0`).ReplaceAllString(text, "Number of constraint states: 1")
+
+	// Discrete and constraint states are read into their own slices
+	ld, err := lin.ReadLin(strings.NewReader(text), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(ld.OP_x), 8; act != exp {
+		t.Fatalf("len(ld.OP_x) = %v, expected %v", act, exp)
+	}
+	if act, exp := ld.OP_xd.Values(), []float64{1, 2}; !reflect.DeepEqual(act, exp) {
+		t.Fatalf("ld.OP_xd.Values() = %v, expected %v", act, exp)
+	}
+	if act, exp := ld.OP_z.Descs(), []string{"SrvD constraint state 1"}; !reflect.DeepEqual(act, exp) {
+		t.Fatalf("ld.OP_z.Descs() = %v, expected %v", act, exp)
+	}
+
+	// Continuous states are unchanged
+	md := lin.NewMatData([]*lin.LinData{ld})
+	if act, exp := len(md.OP_x), 8; act != exp {
+		t.Fatalf("len(md.OP_x) = %v, expected %v", act, exp)
+	}
+	if act, exp := len(md.OP_xd), 2; act != exp {
+		t.Fatalf("len(md.OP_xd) = %v, expected %v", act, exp)
+	}
+
+	// Number of states different from header returns error
+	text = strings.Replace(text, "Number of discrete states: 2", "Number of discrete states: 3", 1)
+	if _, err := lin.ReadLin(strings.NewReader(text), 1); err == nil {
+		t.Fatal("expected error when number of discrete states doesn't match header")
+	}
+}
+
+func TestReadLinHeaderLongLine(t *testing.T) {
+
+	// Replace description with a line longer than the default scanner buffer
+	bs, err := os.ReadFile("testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := "Description from the FAST input file: " + strings.Repeat("x", 100000)
+	bs = regexp.MustCompile(`Description from the FAST input file:.*`).ReplaceAll(bs, []byte(desc))
+	path := filepath.Join(t.TempDir(), "long.1.lin")
+	if err := os.WriteFile(path, bs, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	ld, err := lin.ReadLinHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := ld.Num_x, 8; act != exp {
+		t.Fatalf("ld.Num_x = %v, expected %v", act, exp)
+	}
+}
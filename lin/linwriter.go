@@ -0,0 +1,147 @@
+package lin
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// WriteLinFile writes the linearization data to filePath in the OpenFAST
+// text format. If the path ends in ".gz" the file is gzip compressed.
+func WriteLinFile(filePath string, ld *LinData) error {
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Compress file if requested
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if strings.HasSuffix(strings.ToLower(filePath), ".gz") {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+
+	if err := WriteLin(w, ld); err != nil {
+		return fmt.Errorf("error writing '%s': %w", filePath, err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("error writing '%s': %w", filePath, err)
+		}
+	}
+
+	return f.Close()
+}
+
+// WriteLin writes the linearization data to w in the OpenFAST text format.
+// Values are written with enough digits that reading the output with ReadLin
+// returns the same data.
+func WriteLin(w io.Writer, ld *LinData) error {
+
+	bw := bufio.NewWriter(w)
+
+	//--------------------------------------------------------------------------
+	// Header
+	//--------------------------------------------------------------------------
+
+	hasJac := ld.DUdu != nil || ld.DUdy != nil
+	jacIncluded := "No"
+	if hasJac {
+		jacIncluded = "Yes"
+	}
+	fmt.Fprintf(bw, "Linearized model: written by ACDC\n\n")
+	fmt.Fprintf(bw, "Simulation information:\n")
+	fmt.Fprintf(bw, "   Simulation time:                     %s s\n", formatLinFloat(ld.SimTime))
+	fmt.Fprintf(bw, "   Rotor Speed:                         %s rad/s\n", formatLinFloat(ld.RotorSpeed))
+	fmt.Fprintf(bw, "   Azimuth:                             %s rad\n", formatLinFloat(ld.Azimuth))
+	fmt.Fprintf(bw, "   Wind Speed:                          %s m/s\n", formatLinFloat(ld.WindSpeed))
+	fmt.Fprintf(bw, "   Number of continuous states:  %10d\n", ld.Num_x)
+	fmt.Fprintf(bw, "   Number of discrete states:    %10d\n", ld.Num_xd)
+	fmt.Fprintf(bw, "   Number of constraint states:  %10d\n", ld.Num_z)
+	fmt.Fprintf(bw, "   Number of inputs:             %10d\n", ld.Num_u)
+	fmt.Fprintf(bw, "   Number of outputs:            %10d\n", ld.Num_y)
+	fmt.Fprintf(bw, "   Jacobians included in this file?   %s\n\n", jacIncluded)
+
+	//--------------------------------------------------------------------------
+	// Operating points
+	//--------------------------------------------------------------------------
+
+	sections := []struct {
+		title string
+		label string
+		ops   OPSlice
+	}{
+		{"Order of continuous states:", "Row/Column", ld.OP_x},
+		{"Order of continuous state derivatives:", "Row/Column", ld.OP_xdot},
+		{"Order of discrete states:", "Row/Column", ld.OP_xd},
+		{"Order of constraint states:", "Row/Column", ld.OP_z},
+		{"Order of inputs:", "    Column", ld.OP_u},
+		{"Order of outputs:", "       Row", ld.OP_y},
+	}
+	for _, s := range sections {
+		if len(s.ops) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "%s\n", s.title)
+		fmt.Fprintf(bw, "   %s Operating Point            Rotating Frame? Derivative Order Description\n", s.label)
+		fmt.Fprintf(bw, "   ---------- ---------------            --------------- ---------------- -----------\n")
+		for _, op := range s.ops {
+			rotFrame := "F"
+			if op.IsRotFrame {
+				rotFrame = "T"
+			}
+			fmt.Fprintf(bw, "   %10d %-25s  %15s %16d %s\n", op.Index+1, formatLinFloat(op.Value),
+				rotFrame, op.DerivOrder, op.Desc)
+		}
+		fmt.Fprintf(bw, "\n")
+	}
+
+	//--------------------------------------------------------------------------
+	// Matrices
+	//--------------------------------------------------------------------------
+
+	if hasJac {
+		fmt.Fprintf(bw, "\nJacobian matrices:\n\n")
+		writeLinMatrix(bw, "dUdu", ld.DUdu)
+		writeLinMatrix(bw, "dUdy", ld.DUdy)
+	}
+
+	fmt.Fprintf(bw, "\nLinearized state matrices:\n\n")
+	writeLinMatrix(bw, "A", ld.A)
+	writeLinMatrix(bw, "B", ld.B)
+	writeLinMatrix(bw, "C", ld.C)
+	writeLinMatrix(bw, "D", ld.D)
+
+	return bw.Flush()
+}
+
+// writeLinMatrix writes the matrix name and dimensions followed by its rows.
+// Nil or empty matrices aren't written.
+func writeLinMatrix(bw *bufio.Writer, name string, m *mat.Dense) {
+	if m == nil || m.IsEmpty() {
+		return
+	}
+	rows, cols := m.Dims()
+	fmt.Fprintf(bw, "%s: %d x %d\n", name, rows, cols)
+	for i := range rows {
+		for j := range cols {
+			fmt.Fprintf(bw, "  %s", formatLinFloat(m.At(i, j)))
+		}
+		fmt.Fprintf(bw, "\n")
+	}
+	fmt.Fprintf(bw, "\n")
+}
+
+// formatLinFloat formats the value in scientific notation with enough digits
+// to be parsed back to the same value.
+func formatLinFloat(v float64) string {
+	return fmt.Sprintf("%23.16E", v)
+}
@@ -0,0 +1,97 @@
+package lin_test
+
+import (
+	"acdc/lin"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestWriteLin(t *testing.T) {
+
+	paths, err := filepath.Glob("testdata/*.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+
+			ld, err := lin.ReadLinFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Write and read back data
+			buf := &bytes.Buffer{}
+			if err := lin.WriteLin(buf, ld); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			ld2, err := lin.ReadLin(buf, ld.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ld, ld2) {
+				t.Fatal("data read from written file differs from original")
+			}
+
+			// Writing again produces the same output
+			buf.Reset()
+			if err := lin.WriteLin(buf, ld2); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != out {
+				t.Fatal("second write differs from first")
+			}
+		})
+	}
+
+	// Modified data can be written to a compressed file and read back
+	ld, err := lin.ReadLinFile("testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ld.Azimuth = 2.0944
+	ld.A.Set(0, 0, -1.5e-7)
+	path := filepath.Join(t.TempDir(), "edited.2.lin.gz")
+	if err := lin.WriteLinFile(path, ld); err != nil {
+		t.Fatal(err)
+	}
+	ld2, err := lin.ReadLinFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ld2.ID != 2 || ld2.Azimuth != ld.Azimuth || ld2.A.At(0, 0) != ld.A.At(0, 0) {
+		t.Fatal("edited data not read back from compressed file")
+	}
+
+	// Matrices with rows longer than the default scanner buffer are read back
+	const numInputs = 5000
+	ld.Num_u = numInputs
+	ld.OP_u = make(lin.OPSlice, numInputs)
+	for i := range ld.OP_u {
+		ld.OP_u[i] = lin.OPData{Index: i, Value: float64(i), DerivOrder: 0, Desc: fmt.Sprintf("Input %d", i+1)}
+	}
+	ld.B = mat.NewDense(ld.Num_x, numInputs, nil)
+	ld.D = mat.NewDense(ld.Num_y, numInputs, nil)
+	for j := range numInputs {
+		ld.B.Set(0, j, 1+float64(j)/3)
+		ld.D.Set(0, j, -float64(j)/7)
+	}
+	buf := &bytes.Buffer{}
+	if err := lin.WriteLin(buf, ld); err != nil {
+		t.Fatal(err)
+	}
+	ld2, err = lin.ReadLin(buf, ld.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ld, ld2) {
+		t.Fatal("data read from written wide file differs from original")
+	}
+}
@@ -1,6 +1,7 @@
 package lin
 
 import (
+	"fmt"
 	"math"
 	"regexp"
 	"sort"
@@ -18,7 +19,9 @@ type MatData struct {
 	OmegaDot         []float64
 	WindSpeed        []float64
 	A, B, C, D       []*mat.Dense
+	DUdu, DUdy       []*mat.Dense // Glue-code input/output Jacobians, nil if not in the files
 	OP_x, OP_u, OP_y OPSlice
+	OP_xd, OP_z      OPSlice // Discrete and constraint states, excluded from MBC
 	OpX              *mat.Dense
 	OpXd             *mat.Dense
 	OpU              *mat.Dense
@@ -131,7 +134,123 @@ func CombineOPOrders(opOrders ...OPOrder) OPOrder {
 	return opOrder
 }
 
-// NewMatData returns a structure initialized from the given linearization data
+// ValidateLinData returns an error if the linearization data read from the
+// files of one operating point can't be combined. All files must have the
+// same states, in the same order, the same numbers of inputs and outputs and
+// matrix dimensions, the same rotor and wind speed, and different azimuths.
+// Input/output Jacobians must be in all files or none.
+// The error names the file which differs from the first file.
+func ValidateLinData(lds []*LinData, filePaths []string) error {
+
+	if len(lds) == 0 {
+		return fmt.Errorf("no linearization data")
+	}
+	if len(filePaths) != len(lds) {
+		return fmt.Errorf("got %d file paths for %d linearization files", len(filePaths), len(lds))
+	}
+
+	// Check speeds, numbers of states, and azimuths from headers
+	if err := validateHeaders(lds, filePaths); err != nil {
+		return err
+	}
+
+	ref, refPath := lds[0], filePaths[0]
+	for i, ld := range lds[1:] {
+		path := filePaths[i+1]
+
+		// States must match in number and order
+		if len(ld.OP_x) != len(ref.OP_x) {
+			return fmt.Errorf("'%s' has %d continuous states, '%s' has %d",
+				path, len(ld.OP_x), refPath, len(ref.OP_x))
+		}
+		for j, op := range ld.OP_x {
+			if op.Desc != ref.OP_x[j].Desc {
+				return fmt.Errorf("continuous state %d in '%s' is '%s', expected '%s' as in '%s'",
+					j+1, path, op.Desc, ref.OP_x[j].Desc, refPath)
+			}
+		}
+
+		// Numbers of inputs and outputs must match
+		if len(ld.OP_u) != len(ref.OP_u) || len(ld.OP_y) != len(ref.OP_y) {
+			return fmt.Errorf("'%s' has %d inputs and %d outputs, '%s' has %d and %d",
+				path, len(ld.OP_u), len(ld.OP_y), refPath, len(ref.OP_u), len(ref.OP_y))
+		}
+
+		// Input/output Jacobians must be in all files or none, so they
+		// aren't silently dropped when some files don't contain them
+		if (ld.DUdu != nil) != (ref.DUdu != nil) || (ld.DUdy != nil) != (ref.DUdy != nil) {
+			return fmt.Errorf("input/output Jacobians are in only one of '%s' and '%s', enable LinOutJac for all files",
+				path, refPath)
+		}
+
+		// Matrix dimensions must match
+		for _, m := range []struct {
+			name     string
+			act, exp *mat.Dense
+		}{
+			{"A", ld.A, ref.A}, {"B", ld.B, ref.B}, {"C", ld.C, ref.C}, {"D", ld.D, ref.D},
+			{"dUdu", ld.DUdu, ref.DUdu}, {"dUdy", ld.DUdy, ref.DUdy},
+		} {
+			if !sameDims(m.act, m.exp) {
+				return fmt.Errorf("%s matrix in '%s' has different dimensions than in '%s'", m.name, path, refPath)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateHeaders returns an error if the header data of the files of one
+// operating point can't be combined. The rotor and wind speeds and numbers of
+// states must match the first file and the azimuths must all differ. Files
+// are identified in the error by the given names. It's used by discovery,
+// which only reads the headers, as well as ValidateLinData.
+func validateHeaders(lds []*LinData, names []string) error {
+
+	ref, refName := lds[0], names[0]
+	for i, ld := range lds[1:] {
+		name := names[i+1]
+
+		// Rotor and wind speed must match
+		if !closeTo(ld.RotorSpeed, ref.RotorSpeed) {
+			return fmt.Errorf("rotor speed %g rad/s in '%s' differs from %g rad/s in '%s'",
+				ld.RotorSpeed, name, ref.RotorSpeed, refName)
+		}
+		if !closeTo(ld.WindSpeed, ref.WindSpeed) {
+			return fmt.Errorf("wind speed %g m/s in '%s' differs from %g m/s in '%s'",
+				ld.WindSpeed, name, ref.WindSpeed, refName)
+		}
+
+		// Numbers of states must match
+		if ld.Num_x != ref.Num_x || ld.Num_xd != ref.Num_xd || ld.Num_z != ref.Num_z {
+			return fmt.Errorf("'%s' has %d continuous, %d discrete, and %d constraint states, '%s' has %d, %d, and %d",
+				name, ld.Num_x, ld.Num_xd, ld.Num_z, refName, ref.Num_x, ref.Num_xd, ref.Num_z)
+		}
+
+		// Azimuth must differ from all previous files
+		for j, other := range lds[:i+1] {
+			if sameAzimuth(ld.Azimuth, other.Azimuth) {
+				return fmt.Errorf("azimuth %g rad in '%s' is the same as in '%s'",
+					ld.Azimuth, name, names[j])
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameDims returns true if both matrices are nil or have the same dimensions.
+func sameDims(a, b *mat.Dense) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ra, ca := a.Dims()
+	rb, cb := b.Dims()
+	return ra == rb && ca == cb
+}
+
+// NewMatData returns a structure initialized from the given linearization
+// data, which should be checked with ValidateLinData first.
 func NewMatData(lds []*LinData) *MatData {
 
 	// Sort linearization file data by azimuth
@@ -156,6 +275,8 @@ func NewMatData(lds []*LinData) *MatData {
 		OP_x:       ld.OP_x,
 		OP_u:       ld.OP_u,
 		OP_y:       ld.OP_y,
+		OP_xd:      ld.OP_xd,
+		OP_z:       ld.OP_z,
 	}
 
 	//--------------------------------------------------------------------------
@@ -244,5 +365,16 @@ func NewMatData(lds []*LinData) *MatData {
 		}
 	}
 
+	// Input/output Jacobians are stored if the files contain them,
+	// ValidateLinData checks that they're in all files or none
+	if len(md.OP_u) > 0 && len(md.OP_y) > 0 && ld.DUdu != nil && ld.DUdy != nil {
+		md.DUdu = make([]*mat.Dense, numStep)
+		md.DUdy = make([]*mat.Dense, numStep)
+		for i, ld := range lds {
+			md.DUdu[i] = mat.DenseCopyOf(ld.DUdu) // (NumU,NumU)
+			md.DUdy[i] = mat.DenseCopyOf(ld.DUdy) // (NumU,NumY)
+		}
+	}
+
 	return md
 }
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"acdc/diagram"
 	"embed"
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/carlmjohnson/versioninfo"
 	"github.com/labstack/gommon/log"
@@ -21,12 +24,31 @@ var version = "0.6.0-" + versioninfo.Short()
 func main() {
 
 	showVersion := flag.Bool("version", false, "display version information")
+	diagramPath := flag.String("diagram", "", "path to diagram file (diagram.json) or linearization directory to render")
+	imagePath := flag.String("image", "", "path to write rendered diagram image (.svg or .png)")
+	renderOpts := diagram.NewRenderOptions()
+	flag.IntVar(&renderOpts.Width, "width", renderOpts.Width, "rendered image width in pixels")
+	flag.IntVar(&renderOpts.Height, "height", renderOpts.Height, "rendered image height in pixels")
+	flag.StringVar(&renderOpts.Title, "title", "", "rendered diagram title")
+	flag.StringVar(&renderOpts.Units.Frequency, "freq-units", renderOpts.Units.Frequency, "rendered frequency units: Hz, rad/s, or PerRev")
+	flag.StringVar(&renderOpts.Units.Damping, "damp-units", renderOpts.Units.Damping, "rendered damping units: Ratio, Percent, or LogDec")
+	flag.BoolVar(&renderOpts.Units.Damped, "damped", false, "render damped instead of natural frequency")
+	flag.BoolVar(&renderOpts.ShowHidden, "show-hidden", false, "render hidden diagram lines")
 	flag.Parse()
 	if *showVersion {
 		fmt.Println(version)
 		return
 	}
 
+	// Render diagram image without starting the app
+	if *diagramPath != "" || *imagePath != "" {
+		if err := renderDiagram(*diagramPath, *imagePath, renderOpts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -55,3 +77,25 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// renderDiagram renders the diagram at the path to an image file. The path
+// may be a diagram file or a linearization directory containing one.
+func renderDiagram(diagramPath, imagePath string, opts diagram.RenderOptions) error {
+
+	if diagramPath == "" || imagePath == "" {
+		return fmt.Errorf("both -diagram and -image are required to render a diagram")
+	}
+
+	// If path is a directory, use the diagram file in it
+	if info, err := os.Stat(diagramPath); err == nil && info.IsDir() {
+		diagramPath = filepath.Join(diagramPath, "diagram.json")
+	}
+
+	// Load diagram
+	diag, err := LoadDiagram(diagramPath)
+	if err != nil {
+		return fmt.Errorf("error loading diagram '%s': %w", diagramPath, err)
+	}
+
+	return diag.RenderFile(imagePath, opts)
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// migration upgrades the decoded JSON data of a file by one schema version
+type migration func(data map[string]any) error
+
+// schema describes a versioned file format. The current version of the
+// format is the number of migrations, so adding a migration to the end of
+// the slice increments the version.
+type schema struct {
+	Name       string
+	VersionKey []string
+	Migrations []migration
+}
+
+// Version returns the current version of the file format
+func (s schema) Version() int {
+	return len(s.Migrations)
+}
+
+// Migrate decodes the JSON data, applies the migrations required to bring it
+// to the current version, and returns the encoded result. Data written by a
+// newer version of the format returns an error as it can't be read safely.
+func (s schema) Migrate(bs []byte) ([]byte, error) {
+
+	// Decode data into generic map
+	data := map[string]any{}
+	if err := json.Unmarshal(bs, &data); err != nil {
+		return nil, err
+	}
+
+	// Get file version, zero if not present
+	version := 0
+	if v, ok := jsonLookup(data, s.VersionKey).(float64); ok {
+		version = int(v)
+	}
+
+	// If file is newer than the current version, return error
+	if version > s.Version() {
+		return nil, fmt.Errorf("%s file version %d is newer than supported version %d, update ACDC to open it",
+			s.Name, version, s.Version())
+	}
+
+	// If file is current, return original data
+	if version == s.Version() {
+		return bs, nil
+	}
+
+	// Apply migrations in order
+	for i, m := range s.Migrations[version:] {
+		if err := m(data); err != nil {
+			return nil, fmt.Errorf("error migrating %s file to version %d: %w", s.Name, version+i+1, err)
+		}
+	}
+
+	// Set version in data
+	if err := jsonSet(data, s.VersionKey, s.Version()); err != nil {
+		return nil, fmt.Errorf("error setting %s file version: %w", s.Name, err)
+	}
+
+	return json.Marshal(data)
+}
+
+// jsonLookup returns the value in data at the path of keys or nil if not found
+func jsonLookup(data map[string]any, keys []string) any {
+	var v any = data
+	for _, key := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// jsonSet sets the value in data at the path of keys, creating objects as needed
+func jsonSet(data map[string]any, keys []string, value any) error {
+	m := data
+	for _, key := range keys[:len(keys)-1] {
+		switch v := m[key].(type) {
+		case map[string]any:
+			m = v
+		case nil:
+			m[key] = map[string]any{}
+			m = m[key].(map[string]any)
+		default:
+			return fmt.Errorf("'%s' is not an object", key)
+		}
+	}
+	m[keys[len(keys)-1]] = value
+	return nil
+}
+
+//------------------------------------------------------------------------------
+// Project
+//------------------------------------------------------------------------------
+
+var projectSchema = schema{
+	Name:       "project",
+	VersionKey: []string{"Info", "SchemaVersion"},
+	Migrations: []migration{
+		migrateProjectCases,
+	},
+}
+
+// migrateProjectCases moves the rotor speed range, wind speed range, and curve
+// from the Structure and AeroStructure objects into the case and populates
+// case fields that didn't exist in earlier versions with their default values.
+func migrateProjectCases(data map[string]any) error {
+
+	analysis, ok := data["Analysis"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	cases, ok := analysis["Cases"].([]any)
+	if !ok {
+		return nil
+	}
+
+	for _, v := range cases {
+
+		c, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid case: %v", v)
+		}
+
+		includeAero, _ := c["IncludeAero"].(bool)
+
+		// Move structure data into case
+		if s, ok := c["Structure"].(map[string]any); ok {
+			if _, ok := c["RotorSpeedRange"]; !ok {
+				c["RotorSpeedRange"] = s["RotorSpeedRange"]
+			}
+			if _, ok := c["Curve"]; !ok && !includeAero {
+				c["Curve"] = s["Curve"]
+			}
+			delete(c, "Structure")
+		}
+
+		// Move aero structure data into case
+		if s, ok := c["AeroStructure"].(map[string]any); ok {
+			if _, ok := c["WindSpeedRange"]; !ok {
+				c["WindSpeedRange"] = s["WindSpeedRange"]
+			}
+			if _, ok := c["Curve"]; !ok && includeAero {
+				c["Curve"] = s["Curve"]
+			}
+			delete(c, "AeroStructure")
+		}
+
+		// Populate missing fields with default values
+		for key, value := range caseDefaultsV1() {
+			if c[key] == nil {
+				c[key] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// caseDefaultsV1 returns the default case values of schema version 1 as
+// decoded JSON. These must not change when the defaults of NewCase change.
+func caseDefaultsV1() map[string]any {
+	condition := func(id, windSpeed, rotorSpeed float64) map[string]any {
+		return map[string]any{"ID": id, "WindSpeed": windSpeed, "RotorSpeed": rotorSpeed, "BladePitch": 0.0}
+	}
+	return map[string]any{
+		"ID":              1.0,
+		"Name":            "Base",
+		"IncludeAero":     false,
+		"UseController":   false,
+		"RotorSpeedRange": map[string]any{"Min": 1.0, "Max": 20.0, "Num": 5.0},
+		"WindSpeedRange":  map[string]any{"Min": 1.0, "Max": 20.0, "Num": 5.0},
+		"RatedWindSpeed":  10.0,
+		"RatedRotorSpeed": 0.0,
+		"TrimGain":        []any{100.0, 0.00001},
+		"Curve": []any{
+			condition(1, 1, 1),
+			condition(2, 20, 10),
+		},
+		"OperatingPoints": []any{
+			condition(0, 0, 1),
+			condition(1, 0, 5.75),
+			condition(2, 0, 10.5),
+			condition(3, 0, 15.25),
+			condition(4, 0, 20),
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+// Results
+//------------------------------------------------------------------------------
+
+var resultsSchema = schema{
+	Name:       "results",
+	VersionKey: []string{"SchemaVersion"},
+	Migrations: []migration{
+		migrateUnversioned,
+		migrateResultsDiscover,
+	},
+}
+
+// migrateResultsDiscover adds the discovery options which weren't recorded
+// in earlier versions. Earlier versions only found files in the top directory
+// and grouped them by name, which are the version 2 defaults.
+func migrateResultsDiscover(data map[string]any) error {
+	if _, ok := data["Discover"]; !ok {
+		data["Discover"] = map[string]any{
+			"Recursive": false,
+			"Patterns":  []any{"*.lin"},
+			"GroupBy":   "Name",
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+// Diagram
+//------------------------------------------------------------------------------
+
+var diagramSchema = schema{
+	Name:       "diagram",
+	VersionKey: []string{"SchemaVersion"},
+	Migrations: []migration{
+		migrateUnversioned,
+		migrateDiagramLinkScores,
+		migrateDiagramOptions,
+	},
+}
+
+// migrateDiagramLinkScores adds the link scores which weren't recorded in
+// earlier versions. Points have no runner-up, so no links are flagged as
+// low confidence.
+func migrateDiagramLinkScores(data map[string]any) error {
+
+	lines, _ := data["Lines"].([]any)
+	for _, v := range lines {
+		line, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid line: %v", v)
+		}
+		points, _ := line["Points"].([]any)
+		for _, v := range points {
+			p, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("invalid point: %v", v)
+			}
+			if _, ok := p["RunnerUpMode"]; !ok {
+				p["RunnerUpMode"] = -1
+			}
+		}
+		for _, key := range []string{"LowConfidence", "Swaps"} {
+			if line[key] == nil {
+				line[key] = []any{}
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateDiagramOptions adds the generation options which weren't recorded
+// in earlier versions. The options are set to the defaults with a frequency
+// range covering all points.
+func migrateDiagramOptions(data map[string]any) error {
+
+	if _, ok := data["Options"]; ok {
+		return nil
+	}
+
+	// Get maximum frequency of points
+	maxFreq := 0.0
+	lines, _ := data["Lines"].([]any)
+	for _, v := range lines {
+		line, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid line: %v", v)
+		}
+		points, _ := line["Points"].([]any)
+		for _, v := range points {
+			p, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("invalid point: %v", v)
+			}
+			if f, ok := p["NaturalFreqHz"].(float64); ok {
+				maxFreq = max(maxFreq, f)
+			}
+		}
+	}
+
+	// Add version 3 default options covering the frequency range of the points.
+	// These must not change when the defaults of diagram.NewOptions change.
+	if maxFreq > 0 {
+		maxFreq = math.Ceil(maxFreq)
+	} else {
+		maxFreq = 10
+	}
+	optsMap := map[string]any{
+		"MinFreq":          0.0,
+		"MaxFreq":          maxFreq,
+		"Cluster":          false,
+		"FilterStruct":     false,
+		"Metric":           "MAC",
+		"FreqWeight":       1.0,
+		"DampWeight":       0.0,
+		"MinCorrelation":   0.0,
+		"Tracking":         "Sequential",
+		"LookAhead":        2.0,
+		"ConfidenceMargin": 0.1,
+	}
+	data["Options"] = optsMap
+
+	return nil
+}
+
+// migrateUnversioned upgrades files written before versioning was added,
+// the layout of these files is the same as version 1.
+func migrateUnversioned(data map[string]any) error {
+	return nil
+}
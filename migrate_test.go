@@ -0,0 +1,153 @@
+package main
+
+import (
+	"acdc/diagram"
+	"acdc/lin"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrateProject(t *testing.T) {
+
+	// Unversioned project with structure data stored in separate objects
+	projectJSON := `{
+		"Info": {"Date": "", "Path": ""},
+		"Analysis": {
+			"Cases": [
+				{
+					"ID": 1,
+					"Name": "Structural",
+					"IncludeAero": false,
+					"Structure": {
+						"RotorSpeedRange": {"Min": 2, "Max": 12, "Num": 6},
+						"Curve": [
+							{"ID": 1, "WindSpeed": 0, "RotorSpeed": 2, "BladePitch": 0},
+							{"ID": 2, "WindSpeed": 0, "RotorSpeed": 12, "BladePitch": 0}
+						]
+					}
+				}
+			]
+		}
+	}`
+
+	path := filepath.Join(t.TempDir(), "project.json")
+	if err := os.WriteFile(path, []byte(projectJSON), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadProject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := p.Info.SchemaVersion, projectSchema.Version(); act != exp {
+		t.Fatalf("p.Info.SchemaVersion = %v, expected %v", act, exp)
+	}
+	c := p.Analysis.Cases[0]
+	if act, exp := c.RotorSpeedRange, (Range{Min: 2, Max: 12, Num: 6}); act != exp {
+		t.Fatalf("c.RotorSpeedRange = %v, expected %v", act, exp)
+	}
+	if act, exp := len(c.Curve), 2; act != exp {
+		t.Fatalf("len(c.Curve) = %v, expected %v", act, exp)
+	}
+	if act, exp := c.TrimGain, NewCase().TrimGain; act != exp {
+		t.Fatalf("c.TrimGain = %v, expected %v", act, exp)
+	}
+
+	// Saved project should have the current version and load unchanged
+	if _, err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	p, err = LoadProject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := p.Analysis.Cases[0].RotorSpeedRange.Max, 12.0; act != exp {
+		t.Fatalf("RotorSpeedRange.Max = %v, expected %v", act, exp)
+	}
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+
+	_, err := projectSchema.Migrate([]byte(`{"Info": {"SchemaVersion": 1000}}`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected newer version error, got %v", err)
+	}
+
+	_, err = resultsSchema.Migrate([]byte(`{"SchemaVersion": 1000}`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected newer version error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "diagram.json")
+	if err := os.WriteFile(path, []byte(`{"SchemaVersion": 1000, "Lines": []}`), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDiagram(path); err == nil {
+		t.Fatal("expected error loading newer diagram")
+	}
+}
+
+func TestMigrateDiagram(t *testing.T) {
+
+	// Version 1 diagram without link scores or options
+	diagramJSON := `{
+		"SchemaVersion": 1,
+		"RotSpeeds": [5, 10],
+		"WindSpeeds": [0, 0],
+		"Lines": [
+			{"ID": 0, "Label": "Line 1", "Points": [
+				{"Line": 0, "OpPtID": 0, "ModeID": 0, "NaturalFreqHz": 0.3},
+				{"Line": 0, "OpPtID": 1, "ModeID": 0, "NaturalFreqHz": 2.4}
+			]}
+		]
+	}`
+
+	path := filepath.Join(t.TempDir(), "diagram.json")
+	if err := os.WriteFile(path, []byte(diagramJSON), 0777); err != nil {
+		t.Fatal(err)
+	}
+	d, err := LoadDiagram(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if act, exp := d.SchemaVersion, diagramSchema.Version(); act != exp {
+		t.Fatalf("SchemaVersion = %v, expected %v", act, exp)
+	}
+	if act, exp := d.Options.MaxFreq, 3.0; act != exp {
+		t.Fatalf("Options.MaxFreq = %v, expected %v", act, exp)
+	}
+	if act, exp := d.Options.Metric, diagram.MetricMAC; act != exp {
+		t.Fatalf("Options.Metric = %v, expected %v", act, exp)
+	}
+	if act, exp := d.Lines[0].Points[1].RunnerUpMode, -1; act != exp {
+		t.Fatalf("RunnerUpMode = %v, expected %v", act, exp)
+	}
+	if d.Lines[0].LowConfidence == nil || d.Lines[0].Swaps == nil {
+		t.Fatal("line flags should be initialized")
+	}
+}
+
+func TestMigrateResults(t *testing.T) {
+
+	// Version 1 results without discovery options
+	bs, err := resultsSchema.Migrate([]byte(`{"SchemaVersion": 1, "LinDir": "Case01"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Results{}
+	if err := json.Unmarshal(bs, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if act, exp := r.SchemaVersion, resultsSchema.Version(); act != exp {
+		t.Fatalf("SchemaVersion = %v, expected %v", act, exp)
+	}
+	if act, exp := r.Discover, lin.NewDiscoverOptions(); !reflect.DeepEqual(act, exp) {
+		t.Fatalf("Discover = %+v, expected %+v", act, exp)
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 type Model struct {
 	HasAero       bool     `json:"HasAero"`
-	ImportedPaths []string `json:"ImportedPaths"`
+	ImportedPaths []string `json:"ImportedPaths"` // Relative to the main file, or the project if imported from a bundle
 	Files         *Files   `json:"Files"`
 	Notes         []string `json:"Notes"`
 }
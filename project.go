@@ -2,6 +2,7 @@ package main
 
 import (
 	"acdc/diagram"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -17,17 +18,21 @@ type Project struct {
 	Evaluate *Evaluate        `json:"Evaluate"`
 	Results  *Results         `json:"Results"`
 	Diagram  *diagram.Diagram `json:"Diagram"`
+	Runs     []Run            `json:"Runs"`
+	history  *History
 }
 
 type Info struct {
-	Date string `json:"Date"`
-	Path string `json:"Path"`
+	Date          string `json:"Date"`
+	Path          string `json:"Path"`
+	SchemaVersion int    `json:"SchemaVersion"`
 }
 
 func NewProject() *Project {
 	return &Project{
 		Evaluate: NewEvaluate(),
 		Model:    NewModel(),
+		Runs:     []Run{},
 	}
 }
 
@@ -39,6 +44,12 @@ func LoadProject(path string) (*Project, error) {
 		return nil, fmt.Errorf("error reading project: %w", err)
 	}
 
+	// Upgrade project file to current version
+	bs, err = projectSchema.Migrate(bs)
+	if err != nil {
+		return nil, fmt.Errorf("error reading project: %w", err)
+	}
+
 	// Parse project file
 	p := NewProject()
 	if err := json.Unmarshal(bs, p); err != nil {
@@ -48,17 +59,41 @@ func LoadProject(path string) (*Project, error) {
 	// Save project path
 	p.Info.Path = path
 
+	// Record loaded state as start of undo history
+	if err := p.recordHistory(); err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
 
+// Save writes the project, results, and diagram files and records the
+// project state in the undo history.
 func (p *Project) Save() (*Project, error) {
 
 	if p == nil {
 		return nil, fmt.Errorf("project not loaded")
 	}
 
-	// Update time
-	p.Info.Date = time.Now().Format(time.RFC850)
+	// Write project files
+	if err := p.write(); err != nil {
+		return nil, err
+	}
+
+	// Add project state to history
+	if err := p.recordHistory(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// write saves the project, results, and diagram files. Files are only
+// written if their contents have changed.
+func (p *Project) write() error {
+
+	// Set schema version
+	p.Info.SchemaVersion = projectSchema.Version()
 
 	// Create temporary project to save relevant parts
 	pSave := Project{
@@ -66,55 +101,69 @@ func (p *Project) Save() (*Project, error) {
 		Model:    p.Model,
 		Analysis: p.Analysis,
 		Evaluate: p.Evaluate,
+		Runs:     p.Runs,
 	}
 
 	// Convert project to json
 	bs, err := json.MarshalIndent(pSave, "", "\t")
 	if err != nil {
-		return nil, fmt.Errorf("error encoding project: %w", err)
+		return fmt.Errorf("error encoding project: %w", err)
 	}
 
-	// Write project file
-	err = os.WriteFile(p.Info.Path, bs, 0777)
-	if err != nil {
-		return nil, fmt.Errorf("error reading project: %w", err)
+	// If project changed, update time and write project file
+	if old, err := os.ReadFile(p.Info.Path); err != nil || !bytes.Equal(old, bs) {
+		p.Info.Date = time.Now().Format(time.RFC850)
+		pSave.Info = p.Info
+		if bs, err = json.MarshalIndent(pSave, "", "\t"); err != nil {
+			return fmt.Errorf("error encoding project: %w", err)
+		}
+		if err := saveFile(p.Info.Path, bs); err != nil {
+			return fmt.Errorf("error saving project: %w", err)
+		}
 	}
 
 	// If project results has a path, write file
 	if p.Results != nil && p.Results.LinDir != "" {
 
 		// Write results file
+		p.Results.SchemaVersion = resultsSchema.Version()
 		bs, err := json.MarshalIndent(p.Results, "", "\t")
 		if err != nil {
-			return nil, err
+			return err
 		}
-		err = os.WriteFile(filepath.Join(p.Results.LinDir, "results.json"), bs, 0777)
+		err = saveFile(filepath.Join(p.Results.LinDir, "results.json"), bs)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		// If project diagram is not nil, write file
 		if p.Diagram != nil {
 
 			// Write Diagram file
+			p.Diagram.SchemaVersion = diagramSchema.Version()
 			bs, err := json.MarshalIndent(p.Diagram, "", "\t")
 			if err != nil {
-				return nil, err
+				return err
 			}
-			err = os.WriteFile(filepath.Join(p.Results.LinDir, "diagram.json"), bs, 0777)
+			err = saveFile(filepath.Join(p.Results.LinDir, "diagram.json"), bs)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
 
-	return p, nil
+	return nil
 }
 
 func (p *Project) RootPath() string {
 	return strings.TrimSuffix(p.Info.Path, filepath.Ext(p.Info.Path))
 }
 
+// CaseDir returns the path to the directory where the case is evaluated
+func (p *Project) CaseDir(caseID int) string {
+	return filepath.Join(p.RootPath(), fmt.Sprintf("Case%02d", caseID))
+}
+
 func (p *Project) Case(caseID int) (*Case, error) {
 
 	if p.Analysis == nil {
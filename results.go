@@ -1,22 +1,37 @@
 package main
 
 import (
+	"acdc/diagram"
 	"acdc/lin"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type Results struct {
-	LinDir  string           `json:"LinDir"`
-	HasWind bool             `json:"HasWind"`
-	OPs     []OperatingPoint `json:"OPs"`
-	LinOPs  []lin.LinOP      `json:"LinOPs"`
+	SchemaVersion int                  `json:"SchemaVersion"`
+	LinDir        string               `json:"LinDir"`
+	HasWind       bool                 `json:"HasWind"`
+	OPs           []OperatingPoint     `json:"OPs"`
+	LinOPs        []lin.LinOP          `json:"LinOPs"`
+	Ambiguous     []lin.AmbiguousGroup `json:"Ambiguous"` // File groups that weren't processed
+	Discover      lin.DiscoverOptions  `json:"Discover"`  // Options used to find and group files
+	FileKey       string               `json:"FileKey"`   // Key of the results file written with these results
+	file          *lin.ResultsFile     // Results file that unloaded LinOPs are read from
 }
 
+// resultsFileName is the name of the file in the case directory containing
+// the MBC data and modes of all operating points
+const resultsFileName = "results.bin"
+
 type OperatingPoint struct {
 	ID        int      `json:"ID"`
 	Files     []string `json:"Files"`
@@ -44,35 +59,92 @@ func (res *Results) ForApp() *Results {
 	return &results
 }
 
-func ProcessCaseDir(path string) (*Results, error) {
+// Rebase moves all paths in the results from the current results directory
+// to linDir, keeping their location relative to the directory. It's used when
+// results have been moved to a different location.
+func (res *Results) Rebase(linDir string) {
+	oldDir := res.LinDir
+	res.LinDir = linDir
+	for i := range res.OPs {
+		for j, f := range res.OPs[i].Files {
+			res.OPs[i].Files[j] = rebasePath(f, oldDir, linDir)
+		}
+	}
+	for i := range res.LinOPs {
+		linOP := &res.LinOPs[i]
+		linOP.RootPath = rebasePath(linOP.RootPath, oldDir, linDir)
+		for j, f := range linOP.FilePaths {
+			linOP.FilePaths[j] = rebasePath(f, oldDir, linDir)
+		}
+	}
+	for i := range res.Ambiguous {
+		ag := &res.Ambiguous[i]
+		ag.Name = rebasePath(ag.Name, oldDir, linDir)
+		for j, f := range ag.Files {
+			ag.Files[j] = rebasePath(f, oldDir, linDir)
+		}
+	}
+}
 
-	// Search for linearization files
-	LinFiles, err := filepath.Glob(filepath.Join(path, "*.lin"))
+// rebasePath moves path from oldDir to newDir, keeping its location relative
+// to oldDir. If path isn't in oldDir, only its base name is kept.
+func rebasePath(path, oldDir, newDir string) string {
+	rel, err := filepath.Rel(oldDir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		rel = filepath.Base(path)
+	}
+	return filepath.Join(newDir, rel)
+}
+
+// ProcessStatus is sent to the frontend as linearization files are processed
+type ProcessStatus struct {
+	Stage        string `json:"Stage"`
+	OP           string `json:"OP"`
+	NumFiles     int    `json:"NumFiles"`
+	NumFilesRead int    `json:"NumFilesRead"`
+	NumOPs       int    `json:"NumOPs"`
+	NumOPsDone   int    `json:"NumOPsDone"`
+	Error        string `json:"Error"`
+}
+
+var SendProcessStatus = func(ctx context.Context, ps ProcessStatus) {
+	runtime.EventsEmit(ctx, "processStatus", ps)
+}
+
+var ProcessCancel context.CancelCauseFunc = func(_ error) {}
+
+// ProcessCaseDir finds the linearization files in the case directory, groups
+// them into operating points using the discovery options and processes them
+// into results using the processing options. Ambiguous file groups aren't
+// processed and are reported in the results. Processing stops early if ctx
+// is canceled.
+func ProcessCaseDir(ctx context.Context, path string, dopts lin.DiscoverOptions, popts lin.ProcessOptions) (*Results, error) {
+
+	// Find linearization files and group them by operating point
+	discovery, err := lin.Discover(ctx, path, dopts)
 	if err != nil {
 		return nil, err
 	}
-	linRe := regexp.MustCompile(`.+?\.\d+\.lin`)
-	tmp := LinFiles
-	LinFiles = []string{}
-	for _, f := range tmp {
-		if linRe.MatchString(f) {
-			LinFiles = append(LinFiles, f)
+	if len(discovery.Groups) == 0 {
+		reasons := []string{}
+		for _, ag := range discovery.Ambiguous {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", filepath.Base(ag.Name), ag.Reason))
 		}
-	}
-	if len(LinFiles) == 0 {
-		return nil, fmt.Errorf("no linearization files found")
+		return nil, fmt.Errorf("all linearization file groups are ambiguous: %s", strings.Join(reasons, "; "))
 	}
 
 	// Process linearization files into results
-	linResults, err := lin.ProcessFiles(LinFiles)
+	linResults, err := lin.ProcessFileGroups(ctx, discovery.Groups, popts)
 	if err != nil {
 		return nil, err
 	}
 
 	// Initialize results structure
 	results := &Results{
-		LinDir: path,
-		LinOPs: linResults,
+		LinDir:    path,
+		LinOPs:    linResults,
+		Ambiguous: discovery.Ambiguous,
+		Discover:  dopts,
 	}
 
 	// Extract data from linearization results
@@ -102,45 +174,125 @@ func ProcessCaseDir(path string) (*Results, error) {
 	return results, nil
 }
 
+// Save writes the MBC data and modes of all operating points to the results
+// file, then the results JSON file with the results file's key. The results
+// file is written first so a JSON file is never paired with a results file
+// written before it; if writing stops between the files, the keys differ
+// and LoadResults returns an error.
 func (r *Results) Save(caseDir string) error {
 
-	// Write results data to file
-	bs, err := json.MarshalIndent(r, "", "\t")
+	// Load any operating points that haven't been read from the results file,
+	// which is replaced below
+	if err := r.LoadLinOPs(); err != nil {
+		return err
+	}
+
+	// Write MBC data and modes of all operating points to results file
+	buf := &bytes.Buffer{}
+	key, err := lin.WriteResultsFile(buf, r.LinOPs)
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(filepath.Join(caseDir, "results.json"), bs, 0777)
+	if err := writeFileAtomic(filepath.Join(caseDir, resultsFileName), buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing results file: %w", err)
+	}
+	r.file = nil
+
+	// Write results data with key of results file
+	r.SchemaVersion = resultsSchema.Version()
+	r.FileKey = key
+	bs, err := json.MarshalIndent(r, "", "\t")
 	if err != nil {
 		return err
 	}
+	return saveFile(filepath.Join(caseDir, "results.json"), bs)
+}
 
-	// Loop through linearization OPs
-	for _, linOP := range r.LinOPs {
+// LinOP returns the linearization results of operating point i, reading the
+// MBC data and modes from the results file if they haven't been loaded.
+func (r *Results) LinOP(i int) (*lin.LinOP, error) {
 
-		linOP.RootPath = filepath.Join(caseDir, filepath.Base(linOP.RootPath))
+	if i < 0 || i >= len(r.LinOPs) {
+		return nil, fmt.Errorf("invalid operating point ID: %d", i)
+	}
 
-		// Write MBC data to file
-		bs, err := json.MarshalIndent(linOP.MBC, "", "\t")
+	linOP := &r.LinOPs[i]
+	if linOP.MBC == nil && r.file != nil {
+		mbc, modes, err := r.file.ReadOP(i)
 		if err != nil {
+			return nil, err
+		}
+		linOP.MBC, linOP.Modes = mbc, modes
+	}
+
+	return linOP, nil
+}
+
+// LoadLinOPs reads the MBC data and modes of all operating points which
+// haven't been loaded from the results file.
+func (r *Results) LoadLinOPs() error {
+	for i := range r.LinOPs {
+		if _, err := r.LinOP(i); err != nil {
 			return err
 		}
-		err = os.WriteFile(linOP.RootPath+"_mbc.json", bs, 0777)
+	}
+	return nil
+}
+
+// ExportModesCSV writes the modes of each operating point, including
+// eigenvectors, to a CSV file in dir named after the operating point's path
+// relative to the linearization directory, with separators replaced by
+// underscores so operating points in different subdirectories don't collide.
+func (r *Results) ExportModesCSV(dir string) error {
+	paths := map[string]string{}
+	for i := range r.LinOPs {
+		linOP, err := r.LinOP(i)
 		if err != nil {
 			return err
 		}
 
-		// Write Eigen analysis mode results data to file
+		// Get file name from path relative to linearization directory,
+		// use the base name if the OP isn't in the directory
+		name := filepath.Base(linOP.RootPath)
+		if rel, err := filepath.Rel(r.LinDir, linOP.RootPath); err == nil && filepath.IsLocal(rel) {
+			name = strings.ReplaceAll(filepath.ToSlash(rel), "/", "_")
+		}
+		path := filepath.Join(dir, name+"_modes.csv")
+		if prev, ok := paths[path]; ok {
+			return fmt.Errorf("operating points '%s' and '%s' would both be exported to '%s'",
+				prev, linOP.RootPath, path)
+		}
+		paths[path] = linOP.RootPath
+
 		w := &bytes.Buffer{}
 		linOP.Modes.ToCSV(w)
-		err = os.WriteFile(linOP.RootPath+"_modes.csv", w.Bytes(), 0777)
-		if err != nil {
-			return err
+		if err := os.WriteFile(path, w.Bytes(), 0777); err != nil {
+			return fmt.Errorf("error writing '%s': %w", path, err)
 		}
 	}
-
 	return nil
 }
 
+// LoadDiagram reads the diagram file at path, upgrading it to the current
+// version if necessary.
+func LoadDiagram(path string) (*diagram.Diagram, error) {
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bs, err = diagramSchema.Migrate(bs); err != nil {
+		return nil, err
+	}
+
+	d := diagram.Diagram{}
+	if err := json.Unmarshal(bs, &d); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
 func LoadResults(linDir string) (*Results, error) {
 
 	// Create results structure
@@ -151,37 +303,43 @@ func LoadResults(linDir string) (*Results, error) {
 	if err != nil {
 		return nil, err
 	}
+	if bs, err = resultsSchema.Migrate(bs); err != nil {
+		return nil, err
+	}
 	if err := json.Unmarshal(bs, &r); err != nil {
 		return nil, err
 	}
 
-	// Loop through linearization OPs
+	// Move operating point paths to the directory
 	for i := range r.LinOPs {
-
 		linOP := &r.LinOPs[i]
+		linOP.RootPath = rebasePath(linOP.RootPath, r.LinDir, linDir)
+	}
 
-		linOP.RootPath = filepath.Join(linDir, filepath.Base(linOP.RootPath))
-
-		// Load MBC data
-		bs, err := os.ReadFile(linOP.RootPath + "_mbc.json")
-		if err != nil {
-			return nil, err
+	// Open results file, operating points are read when they're needed
+	r.file, err = lin.OpenResultsFile(filepath.Join(linDir, resultsFileName))
+	if err == nil {
+		if r.file.Key() != r.FileKey {
+			return nil, fmt.Errorf("results file '%s' doesn't match results.json, reprocess the case",
+				filepath.Join(linDir, resultsFileName))
 		}
-		err = json.Unmarshal(bs, &linOP.MBC)
-		if err != nil {
-			return nil, err
+		if r.file.NumOPs() != len(r.LinOPs) {
+			return nil, fmt.Errorf("results file contains %d operating points, expected %d",
+				r.file.NumOPs(), len(r.LinOPs))
 		}
+		return &r, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
 
-		// Write Eigen analysis mode results data to file
-		modesFile := linOP.RootPath + "_modes.csv"
-		bs, err = os.ReadFile(modesFile)
+	// Results file doesn't exist, load MBC data and modes from files
+	// written by previous versions
+	for i := range r.LinOPs {
+		linOP := &r.LinOPs[i]
+		linOP.MBC, linOP.Modes, err = lin.ReadOPResults(linOP.RootPath)
 		if err != nil {
 			return nil, err
 		}
-		linOP.Modes, err = lin.ReadModesCSV(bytes.NewReader(bs))
-		if err != nil {
-			return nil, fmt.Errorf("error parsing '%s': %w", modesFile, err)
-		}
 	}
 
 	return &r, nil
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"acdc/lin"
+	"bytes"
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -11,7 +15,7 @@ func TestLoadResults(t *testing.T) {
 
 	dir := "lin/testdata/bd_aero"
 
-	res, err := ProcessCaseDir(dir)
+	res, err := ProcessCaseDir(context.Background(), dir, lin.NewDiscoverOptions(), lin.NewProcessOptions())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -26,3 +30,83 @@ func TestLoadResults(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestResultsSave(t *testing.T) {
+
+	// Copy linearization file into case directory and process results
+	caseDir := t.TempDir()
+	bs, err := os.ReadFile("lin/testdata/StC_test_OC4Semi_Linear_Tow.1.lin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(caseDir, "Tow.1.lin"), bs, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(caseDir, "sub"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(caseDir, "sub", "Tow.1.lin"), bs, 0777); err != nil {
+		t.Fatal(err)
+	}
+	dopts := lin.NewDiscoverOptions()
+	dopts.Recursive = true
+	results, err := ProcessCaseDir(context.Background(), caseDir, dopts, lin.NewProcessOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := results.Save(caseDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Load results, operating points shouldn't be read until requested
+	res, err := LoadResults(caseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.LinOPs[0].MBC != nil {
+		t.Fatal("operating point loaded before it was requested")
+	}
+	if !reflect.DeepEqual(res.Discover, dopts) {
+		t.Fatalf("res.Discover = %+v, expected %+v", res.Discover, dopts)
+	}
+	linOP, err := res.LinOP(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(linOP.Modes, results.LinOPs[0].Modes) {
+		t.Fatal("loaded modes differ from processed modes")
+	}
+
+	// Export modes to CSV and read them back
+	exportDir := t.TempDir()
+	if err := res.ExportModesCSV(exportDir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Tow_modes.csv", "sub_Tow_modes.csv"} {
+		f, err := os.Open(filepath.Join(exportDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		modes, err := lin.ReadModesCSV(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if act, exp := len(modes), len(linOP.Modes); act != exp {
+			t.Fatalf("len(modes) = %v, expected %v", act, exp)
+		}
+	}
+
+	// Results file written without the results JSON file, as if saving
+	// stopped between them, shouldn't be loaded
+	buf := &bytes.Buffer{}
+	if _, err := lin.WriteResultsFile(buf, results.LinOPs); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(caseDir, "results.bin"), buf.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadResults(caseDir); err == nil {
+		t.Fatal("expected error loading results with mismatched results file")
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// Run contains the metadata for one evaluation of a case. Each run is
+// evaluated in its own directory so previous results are preserved.
+type Run struct {
+	ID          int            `json:"ID"`
+	CaseID      int            `json:"CaseID"`
+	Label       string         `json:"Label"`
+	Dir         string         `json:"Dir"` // Relative to project root path
+	Date        string         `json:"Date"`
+	ExecVersion string         `json:"ExecVersion"` // OpenFAST version used for the run
+	Case        Case           `json:"Case"`        // Case as evaluated
+	Overrides   map[string]any `json:"Overrides"`   // Case settings that differ from the previous run
+	Evaluate    Evaluate       `json:"Evaluate"`    // Evaluation settings and executable
+}
+
+// AddRun creates a new run for the case with a snapshot of the case and
+// evaluation settings, creates the run directory, and saves the project.
+func (p *Project) AddRun(c *Case) (*Run, error) {
+
+	// Get next run ID for this case
+	runID := 1
+	for _, r := range p.Runs {
+		if r.CaseID == c.ID && r.ID >= runID {
+			runID = r.ID + 1
+		}
+	}
+
+	// Copy case so later changes don't modify the run
+	caseCopy, err := c.Copy()
+	if err != nil {
+		return nil, fmt.Errorf("error copying case: %w", err)
+	}
+
+	// Get case settings which changed since the previous run
+	overrides := map[string]any{}
+	if runs := p.CaseRuns(c.ID); len(runs) > 0 {
+		overrides, err = caseOverrides(&runs[len(runs)-1].Case, &caseCopy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Create run
+	run := Run{
+		ID:        runID,
+		CaseID:    c.ID,
+		Label:     fmt.Sprintf("Run %d", runID),
+		Dir:       path.Join(fmt.Sprintf("Case%02d", c.ID), fmt.Sprintf("Run%02d", runID)),
+		Date:      time.Now().Format(time.RFC3339),
+		Case:      caseCopy,
+		Overrides: overrides,
+	}
+	if p.Evaluate != nil {
+		run.Evaluate = *p.Evaluate
+		run.ExecVersion = p.Evaluate.ExecVersion
+	}
+
+	// Create run directory
+	if err := os.MkdirAll(p.RunDir(&run), 0777); err != nil {
+		return nil, fmt.Errorf("error creating directory '%s': %w", p.RunDir(&run), err)
+	}
+
+	// Add run to project and save
+	p.Runs = append(p.Runs, run)
+	if _, err := p.Save(); err != nil {
+		return nil, err
+	}
+
+	return &p.Runs[len(p.Runs)-1], nil
+}
+
+// caseOverrides returns the settings of case c, by JSON field name, whose
+// values differ from those of the base case.
+func caseOverrides(base, c *Case) (map[string]any, error) {
+
+	// Convert cases to generic maps for comparison
+	toMap := func(c *Case) (map[string]any, error) {
+		bs, err := json.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding case: %w", err)
+		}
+		m := map[string]any{}
+		if err := json.Unmarshal(bs, &m); err != nil {
+			return nil, fmt.Errorf("error decoding case: %w", err)
+		}
+		return m, nil
+	}
+	baseMap, err := toMap(base)
+	if err != nil {
+		return nil, err
+	}
+	caseMap, err := toMap(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect settings that differ from base
+	overrides := map[string]any{}
+	for key, value := range caseMap {
+		if !reflect.DeepEqual(value, baseMap[key]) {
+			overrides[key] = value
+		}
+	}
+
+	return overrides, nil
+}
+
+// Run returns the run with the given case and run IDs
+func (p *Project) Run(caseID, runID int) (*Run, error) {
+	for i := range p.Runs {
+		if p.Runs[i].CaseID == caseID && p.Runs[i].ID == runID {
+			return &p.Runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("Run %d of Case %d not found", runID, caseID)
+}
+
+// CaseRuns returns the runs of the given case in the order they were created
+func (p *Project) CaseRuns(caseID int) []Run {
+	runs := []Run{}
+	for _, r := range p.Runs {
+		if r.CaseID == caseID {
+			runs = append(runs, r)
+		}
+	}
+	return runs
+}
+
+// RemoveRun deletes the run directory and removes the run from the project
+func (p *Project) RemoveRun(caseID, runID int) error {
+
+	// Find run
+	run, err := p.Run(caseID, runID)
+	if err != nil {
+		return err
+	}
+
+	// Remove run directory
+	if err := os.RemoveAll(p.RunDir(run)); err != nil {
+		return fmt.Errorf("error removing run directory: %w", err)
+	}
+
+	// Filter out run
+	tmp := []Run{}
+	for _, r := range p.Runs {
+		if r.CaseID != caseID || r.ID != runID {
+			tmp = append(tmp, r)
+		}
+	}
+	p.Runs = tmp
+
+	_, err = p.Save()
+	return err
+}
+
+// RunDir returns the path to the directory where the run is evaluated
+func (p *Project) RunDir(run *Run) string {
+	return filepath.Join(p.RootPath(), filepath.FromSlash(run.Dir))
+}
+
+// ResultsDirs returns the directories which may contain results for the
+// case: the directory of each run followed by the case directory, which
+// contains results evaluated before runs were added.
+func (p *Project) ResultsDirs(caseID int) []string {
+	dirs := []string{}
+	for _, r := range p.CaseRuns(caseID) {
+		dirs = append(dirs, p.RunDir(&r))
+	}
+	return append(dirs, p.CaseDir(caseID))
+}
+
+// LatestResultsDir returns the directory of the most recent run of the case
+// or the case directory if the case has no runs.
+func (p *Project) LatestResultsDir(caseID int) string {
+	runs := p.CaseRuns(caseID)
+	if len(runs) == 0 {
+		return p.CaseDir(caseID)
+	}
+	return p.RunDir(&runs[len(runs)-1])
+}
+
+// RunResultsDir returns the results directory of the run or, if runID is
+// zero, the directory of the latest results of the case.
+func (p *Project) RunResultsDir(caseID, runID int) (string, error) {
+	if runID == 0 {
+		if _, err := p.Case(caseID); err != nil {
+			return "", err
+		}
+		return p.LatestResultsDir(caseID), nil
+	}
+	run, err := p.Run(caseID, runID)
+	if err != nil {
+		return "", err
+	}
+	return p.RunDir(run), nil
+}
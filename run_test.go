@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRuns(t *testing.T) {
+
+	// Create and save project
+	p := NewProject()
+	p.Info.Path = filepath.Join(t.TempDir(), "project.json")
+	p.Analysis = NewAnalysis()
+	if _, err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	c := &p.Analysis.Cases[0]
+
+	// Case without runs uses case directory for results
+	if act, exp := p.LatestResultsDir(c.ID), p.CaseDir(c.ID); act != exp {
+		t.Fatalf("LatestResultsDir = %v, expected %v", act, exp)
+	}
+
+	// Add two runs, changing the case between them
+	p.Evaluate.ExecVersion = "OpenFAST-v3.5.0"
+	run1, err := p.AddRun(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.RatedWindSpeed = 12
+	run2, err := p.AddRun(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if act, exp := run2.ID, 2; act != exp {
+		t.Fatalf("run2.ID = %v, expected %v", act, exp)
+	}
+	if act, exp := run2.ExecVersion, "OpenFAST-v3.5.0"; act != exp {
+		t.Fatalf("run2.ExecVersion = %v, expected %v", act, exp)
+	}
+
+	// Second run should record the settings changed since the first run
+	if act, exp := len(run2.Overrides), 1; act != exp {
+		t.Fatalf("len(run2.Overrides) = %v, expected %v: %v", act, exp, run2.Overrides)
+	}
+	if act, exp := run2.Overrides["RatedWindSpeed"], 12.0; act != exp {
+		t.Fatalf("run2.Overrides[RatedWindSpeed] = %v, expected %v", act, exp)
+	}
+	if act, exp := p.LatestResultsDir(c.ID), p.RunDir(run2); act != exp {
+		t.Fatalf("LatestResultsDir = %v, expected %v", act, exp)
+	}
+	if _, err := os.Stat(p.RunDir(run2)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run should contain snapshot of case when it was created
+	run1, err = p.Run(c.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := run1.Case.RatedWindSpeed, 10.0; act != exp {
+		t.Fatalf("run1.Case.RatedWindSpeed = %v, expected %v", act, exp)
+	}
+
+	// Runs should be saved in project
+	loaded, err := LoadProject(p.Info.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := len(loaded.CaseRuns(c.ID)), 2; act != exp {
+		t.Fatalf("len(CaseRuns) = %v, expected %v", act, exp)
+	}
+
+	// Remove first run
+	runDir := p.RunDir(run1)
+	if err := p.RemoveRun(c.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(runDir); !os.IsNotExist(err) {
+		t.Fatalf("run directory '%s' not removed", runDir)
+	}
+	if act, exp := len(p.CaseRuns(c.ID)), 1; act != exp {
+		t.Fatalf("len(CaseRuns) = %v, expected %v", act, exp)
+	}
+
+	// Next run ID continues after highest remaining run
+	run3, err := p.AddRun(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := run3.ID, 3; act != exp {
+		t.Fatalf("run3.ID = %v, expected %v", act, exp)
+	}
+}